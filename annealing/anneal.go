@@ -0,0 +1,252 @@
+package annealing
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// A value to optimize that is computed from a network
+type Objective func(net *network.AdjacencyList) float64
+
+// The temperature to use at each step
+type Schedule func(step int) float64
+
+// Start at initial and multiply the temperature by decay after every step.
+func ExponentialSchedule(initial, decay float64) Schedule {
+	return func(step int) float64 {
+		return initial * math.Pow(decay, float64(step))
+	}
+}
+
+// Lower the temperature from initial to 0 in a straight line over numSteps steps.
+func LinearSchedule(initial float64, numSteps int) Schedule {
+	return func(step int) float64 {
+		if step >= numSteps {
+			return 0
+		}
+		return initial * float64(numSteps-step) / float64(numSteps)
+	}
+}
+
+type Options struct {
+	Steps    int
+	Schedule Schedule
+	// maximize the objective instead of minimizing it
+	Maximize bool
+	// only make moves that keep the network connected. The starting network must
+	// already be connected.
+	KeepConnected bool
+	// if both are set, the best network so far is written to CheckpointPath in GML
+	// and the trace is written next to it every CheckpointEvery steps and at the end
+	CheckpointPath  string
+	CheckpointEvery int
+}
+
+// What happened at one step of annealing
+type TraceEntry struct {
+	Step        int
+	Temperature float64
+	// the objective of the network after the step
+	Value    float64
+	Best     float64
+	Accepted bool
+}
+
+type Result struct {
+	Best      *network.AdjacencyList
+	BestValue float64
+	Trace     []TraceEntry
+}
+
+// Optimize the objective by simulated annealing. Each step moves one end of a
+// random edge to a random node that the other end isn't connected to, so the
+// number of nodes, the number of edges and the nodes' attributes never change.
+// Moves that make the objective worse are accepted with probability
+// exp(-change/temperature). start isn't changed.
+func Anneal(start *network.AdjacencyList, objective Objective, options Options, rng *rand.Rand) Result {
+	current := start.Copy()
+	if options.KeepConnected && !isConnected(current) {
+		panic("the starting network has to be connected to keep it connected")
+	}
+	mover := newEdgeMover(current, options.KeepConnected, rng)
+	value := objective(current)
+	result := Result{
+		Best:      current.Copy(),
+		BestValue: value,
+		Trace:     make([]TraceEntry, 0, options.Steps),
+	}
+	// the change in the objective is flipped when maximizing so that lower is
+	// always better
+	sign := 1.0
+	if options.Maximize {
+		sign = -1
+	}
+
+	for step := 0; step < options.Steps; step++ {
+		temperature := options.Schedule(step)
+		move, ok := mover.propose()
+		if !ok {
+			break
+		}
+		mover.apply(move)
+		newValue := objective(current)
+		change := sign * (newValue - value)
+		accepted := change <= 0 || (temperature > 0 && rng.Float64() < math.Exp(-change/temperature))
+		if accepted {
+			value = newValue
+			if sign*(value-result.BestValue) < 0 {
+				result.Best = current.Copy()
+				result.BestValue = value
+			}
+		} else {
+			mover.undo(move)
+		}
+		result.Trace = append(result.Trace, TraceEntry{
+			Step:        step,
+			Temperature: temperature,
+			Value:       value,
+			Best:        result.BestValue,
+			Accepted:    accepted,
+		})
+
+		if options.CheckpointPath != "" && options.CheckpointEvery > 0 && (step+1)%options.CheckpointEvery == 0 {
+			checkpoint(options.CheckpointPath, result)
+		}
+	}
+	if options.CheckpointPath != "" && options.CheckpointEvery > 0 {
+		checkpoint(options.CheckpointPath, result)
+	}
+	return result
+}
+
+// Write the best network to path and the trace to path.trace.csv. Annealing can
+// be resumed by reading the network back in and passing it to Anneal.
+func checkpoint(path string, result Result) {
+	fio.WriteGML(path, result.Best)
+	WriteTrace(path+".trace.csv", result.Trace)
+}
+
+// Write the trace to a CSV file with a header row.
+func WriteTrace(path string, trace []TraceEntry) {
+	lines := make([][]string, 0, len(trace)+1)
+	lines = append(lines, []string{"step", "temperature", "value", "best", "accepted"})
+	for _, entry := range trace {
+		lines = append(lines, []string{
+			fmt.Sprint(entry.Step),
+			fmt.Sprint(entry.Temperature),
+			fmt.Sprint(entry.Value),
+			fmt.Sprint(entry.Best),
+			fmt.Sprint(entry.Accepted),
+		})
+	}
+	fio.WriteToCSV(path, lines)
+}
+
+// Moving the end of edge u-v from v to w
+type edgeMove struct {
+	edgeIndex int
+	u, v, w   int64
+}
+
+type edgeMover struct {
+	net           *network.AdjacencyList
+	nodes         []graph.Node
+	edges         [][2]int64
+	keepConnected bool
+	rng           *rand.Rand
+}
+
+func newEdgeMover(net *network.AdjacencyList, keepConnected bool, rng *rand.Rand) *edgeMover {
+	edges := make([][2]int64, 0)
+	for _, e := range graph.EdgesOf(net.Edges()) {
+		if e.From().ID() != e.To().ID() {
+			edges = append(edges, [2]int64{e.From().ID(), e.To().ID()})
+		}
+	}
+	return &edgeMover{
+		net:           net,
+		nodes:         graph.NodesOf(net.Nodes()),
+		edges:         edges,
+		keepConnected: keepConnected,
+		rng:           rng,
+	}
+}
+
+// Find a random move that is allowed. ok is false if one couldn't be found after
+// many tries, which happens when the network is complete or can't be rewired
+// without disconnecting it.
+func (m *edgeMover) propose() (move edgeMove, ok bool) {
+	if len(m.edges) == 0 {
+		return edgeMove{}, false
+	}
+	for tries := 0; tries < 100*len(m.nodes); tries++ {
+		i := m.rng.Intn(len(m.edges))
+		u, v := m.edges[i][0], m.edges[i][1]
+		if m.rng.Intn(2) == 0 {
+			u, v = v, u
+		}
+		w := m.nodes[m.rng.Intn(len(m.nodes))].ID()
+		if w == u || w == v || m.net.HasEdgeBetween(u, w) {
+			continue
+		}
+		move := edgeMove{i, u, v, w}
+		if m.keepConnected {
+			m.apply(move)
+			// the network was connected before, so it is still connected if v can
+			// reach u without the old edge
+			stillConnected := canReach(m.net, v, u)
+			m.undo(move)
+			if !stillConnected {
+				continue
+			}
+		}
+		return move, true
+	}
+	return edgeMove{}, false
+}
+
+func (m *edgeMover) apply(move edgeMove) {
+	m.net.RewireEdge(move.u, move.v, move.w)
+	m.edges[move.edgeIndex] = [2]int64{move.u, move.w}
+}
+
+func (m *edgeMover) undo(move edgeMove) {
+	m.net.RewireEdge(move.u, move.w, move.v)
+	m.edges[move.edgeIndex] = [2]int64{move.u, move.v}
+}
+
+func isConnected(net *network.AdjacencyList) bool {
+	nodes := graph.NodesOf(net.Nodes())
+	if len(nodes) == 0 {
+		return true
+	}
+	return len(reachable(net, nodes[0].ID())) == len(nodes)
+}
+
+func canReach(net *network.AdjacencyList, from, to int64) bool {
+	return reachable(net, from)[to]
+}
+
+// Return the nodes that can be reached from the node using a breadth first search.
+func reachable(net *network.AdjacencyList, from int64) map[int64]bool {
+	seen := map[int64]bool{from: true}
+	queue := []int64{from}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for neighbors := net.From(u); neighbors.Next(); {
+			v := neighbors.Node().ID()
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			queue = append(queue, v)
+		}
+	}
+	return seen
+}
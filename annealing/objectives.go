@@ -0,0 +1,118 @@
+package annealing
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/mat"
+)
+
+// The length of the longest shortest path in the network. Disconnected networks
+// have a diameter of +Inf.
+func Diameter(net *network.AdjacencyList) float64 {
+	nodes := graph.NodesOf(net.Nodes())
+	diameter := 0
+	for _, u := range nodes {
+		distances := hopsFrom(net, u.ID())
+		if len(distances) < len(nodes) {
+			return math.Inf(1)
+		}
+		for _, d := range distances {
+			if d > diameter {
+				diameter = d
+			}
+		}
+	}
+	return float64(diameter)
+}
+
+// Return the number of hops to every node that can be reached from the node.
+func hopsFrom(net *network.AdjacencyList, from int64) map[int64]int {
+	distances := map[int64]int{from: 0}
+	queue := []int64{from}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for neighbors := net.From(u); neighbors.Next(); {
+			v := neighbors.Node().ID()
+			if _, ok := distances[v]; !ok {
+				distances[v] = distances[u] + 1
+				queue = append(queue, v)
+			}
+		}
+	}
+	return distances
+}
+
+// The mean communicability between pairs of distinct nodes. Communicability is
+// the u,v entry of exp(M), which counts every walk between u and v with longer
+// walks counting less. Node IDs have to be 0 to N-1.
+func MeanCommunicability(net *network.AdjacencyList) float64 {
+	N := net.N()
+	if N < 2 {
+		return 0
+	}
+	var expM mat.Dense
+	expM.Exp(net.M())
+	total := 0.0
+	for u := 0; u < N; u++ {
+		for v := 0; v < N; v++ {
+			if u != v {
+				total += expM.At(u, v)
+			}
+		}
+	}
+	return total / float64(N*(N-1))
+}
+
+// The average over every node of the fraction of pairs of its neighbors that are
+// connected. Nodes with fewer than two neighbors count as 0.
+func AverageClustering(net *network.AdjacencyList) float64 {
+	nodes := graph.NodesOf(net.Nodes())
+	if len(nodes) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, u := range nodes {
+		neighbors := graph.NodesOf(net.From(u.ID()))
+		k := len(neighbors)
+		if k < 2 {
+			continue
+		}
+		triangles := 0
+		for i := 0; i < k; i++ {
+			for j := i + 1; j < k; j++ {
+				if net.HasEdgeBetween(neighbors[i].ID(), neighbors[j].ID()) {
+					triangles++
+				}
+			}
+		}
+		total += float64(2*triangles) / float64(k*(k-1))
+	}
+	return total / float64(len(nodes))
+}
+
+// Return an objective that runs numSims simulations on the network and returns
+// the mean survival rate. The simulations run on numWorkers goroutines and use
+// seeds starting at seed, so every network is judged with the same seeds.
+func MeanSurvival(makeSir0 func(N int, numToInfect int, rng *rand.Rand) sim.SIR,
+	disease sim.Disease,
+	makeBehavior func(network.Topology, *rand.Rand) sim.Behavior,
+	maxSteps int,
+	seed int64,
+	numSims int,
+	numWorkers int) Objective {
+
+	return func(net *network.AdjacencyList) float64 {
+		survivalRates := sim.SimOnNetworkForSurvivalRate(net, makeSir0, disease,
+			makeBehavior, maxSteps, seed, numSims, numWorkers)
+		total := 0.0
+		for _, rate := range survivalRates {
+			total += rate
+		}
+		return total / float64(len(survivalRates))
+	}
+}
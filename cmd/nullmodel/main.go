@@ -0,0 +1,83 @@
+// Build a null model class out of an existing class of networks so that the two
+// can be simulated side by side.
+//
+//	nullmodel [-seed 1] [-swaps 10] <degree|community|configuration> <class> [output]
+//
+// The output defaults to a .tar.gz next to the class with a null=<model>
+// parameter added to the class name, such as
+// BarabasiAlbert(N=500,m=2,null=degree).tar.gz, so that ParseClassName reports
+// the null model as another parameter.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+func main() {
+	seed := flag.Int64("seed", 1, "seed for the random number generator")
+	swapsPerEdge := flag.Int("swaps", 10, "number of edge swaps to make per edge for the rewiring models")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <degree|community|configuration> <class> [output]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 || flag.NArg() > 3 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	model := flag.Arg(0)
+	if model != "degree" && model != "community" && model != "configuration" {
+		fmt.Fprintf(os.Stderr, "Unknown null model %s.\n", model)
+		flag.Usage()
+		os.Exit(1)
+	}
+	classPath := flag.Arg(1)
+	outputPath := nullClassPath(classPath, model)
+	if flag.NArg() == 3 {
+		outputPath = flag.Arg(2)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	nets := fio.ReadClass(classPath)
+	for i, net := range nets {
+		numSwaps := *swapsPerEdge * net.Edges().Len()
+		switch model {
+		case "degree":
+			nets[i] = network.DegreePreservingRewire(net, numSwaps, rng)
+		case "community":
+			if _, ok := net.Communities(); !ok {
+				net.SetCommunities(network.Louvain(net, rng))
+			}
+			nets[i] = network.CommunityPreservingRewire(net, numSwaps, rng)
+		case "configuration":
+			nets[i] = network.ConfigurationModel(net, rng)
+		}
+	}
+	fio.WriteClass(outputPath, nets)
+	fmt.Printf("Wrote %d networks to %s.\n", len(nets), outputPath)
+}
+
+// Add null=<model> to the parameters of the class name.
+func nullClassPath(classPath, model string) string {
+	name := filepath.Base(strings.TrimRight(classPath, string(filepath.Separator)))
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	switch {
+	case strings.HasSuffix(name, "()"):
+		name = fmt.Sprintf("%s(null=%s)", strings.TrimSuffix(name, "()"), model)
+	case strings.HasSuffix(name, ")"):
+		name = fmt.Sprintf("%s,null=%s)", strings.TrimSuffix(name, ")"), model)
+	default:
+		name = fmt.Sprintf("%s(null=%s)", name, model)
+	}
+	return filepath.Join(filepath.Dir(classPath), name+".tar.gz")
+}
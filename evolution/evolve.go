@@ -0,0 +1,191 @@
+package evolution
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+// How good a network is. Higher is better. Fitness is evaluated on several
+// goroutines at once, each with its own network. The objectives in the
+// annealing package can be converted to a Fitness, and they can be negated to
+// minimize them.
+type Fitness func(net *network.AdjacencyList) float64
+
+type Options struct {
+	PopulationSize int
+	Generations    int
+	// the number of the best networks that are copied into the next generation unchanged
+	NumElites int
+	// parents are the best of TournamentSize networks picked at random
+	TournamentSize int
+	// the chance that a child is made with Crossover instead of copying a parent
+	CrossoverProbability float64
+	Crossover            Crossover
+	// applied to every child that isn't an elite
+	Mutation Mutation
+	// the number of goroutines used to evaluate fitness
+	NumWorkers int
+	// if set, the best network of each generation is written to
+	// LogDir/generation-<g>.txt in GML and the fitness of each generation is
+	// written to LogDir/log.csv
+	LogDir string
+}
+
+// The fitness of one generation
+type GenerationStats struct {
+	Generation  int
+	BestFitness float64
+	MeanFitness float64
+}
+
+type Result struct {
+	Best        *network.AdjacencyList
+	BestFitness float64
+	History     []GenerationStats
+}
+
+type individual struct {
+	net     *network.AdjacencyList
+	fitness float64
+}
+
+// Evolve a population of networks to maximize fitness. The first generation is
+// made of the seed networks followed by mutations of them until there are
+// PopulationSize networks. The seeds aren't changed. All of the random choices
+// are made on one goroutine, so with a deterministic fitness the same seed gives
+// the same result no matter how many workers there are.
+func Evolve(seeds []*network.AdjacencyList, fitness Fitness, options Options, rng *rand.Rand) Result {
+	if len(seeds) == 0 {
+		panic("at least one seed network is needed")
+	}
+	if options.LogDir != "" {
+		if err := os.MkdirAll(options.LogDir, fs.ModePerm); err != nil {
+			panic(err)
+		}
+	}
+
+	nets := make([]*network.AdjacencyList, options.PopulationSize)
+	for i := range nets {
+		if i < len(seeds) {
+			nets[i] = seeds[i].Copy()
+		} else {
+			nets[i] = options.Mutation(seeds[i%len(seeds)], rng)
+		}
+	}
+	population := evaluate(nets, fitness, options.NumWorkers)
+
+	result := Result{History: make([]GenerationStats, 0, options.Generations)}
+	for generation := 0; ; generation++ {
+		sort.SliceStable(population, func(i, j int) bool {
+			return population[i].fitness > population[j].fitness
+		})
+		stats := GenerationStats{
+			Generation:  generation,
+			BestFitness: population[0].fitness,
+			MeanFitness: meanFitness(population),
+		}
+		result.History = append(result.History, stats)
+		if result.Best == nil || population[0].fitness > result.BestFitness {
+			result.Best = population[0].net
+			result.BestFitness = population[0].fitness
+		}
+		if options.LogDir != "" {
+			logGeneration(options.LogDir, population[0].net, result.History)
+		}
+		if generation+1 >= options.Generations {
+			break
+		}
+		population = nextGeneration(population, fitness, options, rng)
+	}
+	return result
+}
+
+func nextGeneration(population []individual, fitness Fitness, options Options, rng *rand.Rand) []individual {
+	numElites := options.NumElites
+	if numElites > len(population) {
+		numElites = len(population)
+	}
+	children := make([]*network.AdjacencyList, 0, options.PopulationSize-numElites)
+	for len(children) < options.PopulationSize-numElites {
+		a := tournament(population, options.TournamentSize, rng)
+		var child *network.AdjacencyList
+		if options.Crossover != nil && rng.Float64() < options.CrossoverProbability {
+			b := tournament(population, options.TournamentSize, rng)
+			child = options.Crossover(a.net, b.net, rng)
+		} else {
+			child = a.net
+		}
+		children = append(children, options.Mutation(child, rng))
+	}
+
+	next := make([]individual, 0, options.PopulationSize)
+	next = append(next, population[:numElites]...)
+	return append(next, evaluate(children, fitness, options.NumWorkers)...)
+}
+
+// Return the fittest of size individuals chosen at random.
+func tournament(population []individual, size int, rng *rand.Rand) individual {
+	best := population[rng.Intn(len(population))]
+	for i := 1; i < size; i++ {
+		contender := population[rng.Intn(len(population))]
+		if contender.fitness > best.fitness {
+			best = contender
+		}
+	}
+	return best
+}
+
+// Compute the fitness of every network using numWorkers goroutines.
+func evaluate(nets []*network.AdjacencyList, fitness Fitness, numWorkers int) []individual {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	population := make([]individual, len(nets))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				population[i] = individual{nets[i], fitness(nets[i])}
+			}
+		}()
+	}
+	for i := range nets {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return population
+}
+
+func meanFitness(population []individual) float64 {
+	total := 0.0
+	for _, ind := range population {
+		total += ind.fitness
+	}
+	return total / float64(len(population))
+}
+
+func logGeneration(logDir string, best *network.AdjacencyList, history []GenerationStats) {
+	generation := history[len(history)-1].Generation
+	fio.WriteGML(filepath.Join(logDir, fmt.Sprintf("generation-%d.txt", generation)), best)
+	lines := [][]string{{"generation", "best_fitness", "mean_fitness"}}
+	for _, stats := range history {
+		lines = append(lines, []string{
+			fmt.Sprint(stats.Generation),
+			fmt.Sprint(stats.BestFitness),
+			fmt.Sprint(stats.MeanFitness),
+		})
+	}
+	fio.WriteToCSV(filepath.Join(logDir, "log.csv"), lines)
+}
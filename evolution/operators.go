@@ -0,0 +1,144 @@
+package evolution
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Make a changed copy of a network. The parent must not be changed.
+type Mutation func(parent *network.AdjacencyList, rng *rand.Rand) *network.AdjacencyList
+
+// Make a child out of two parents that have the same nodes. The parents must not
+// be changed.
+type Crossover func(a, b *network.AdjacencyList, rng *rand.Rand) *network.AdjacencyList
+
+// Move one end of numMoves random edges to random nodes. The number of edges
+// stays the same, but degrees change.
+func RewireMutation(numMoves int) Mutation {
+	return func(parent *network.AdjacencyList, rng *rand.Rand) *network.AdjacencyList {
+		child := parent.Copy()
+		nodes := graph.NodesOf(child.Nodes())
+		edges := edgePairs(child)
+		if len(edges) == 0 || len(nodes) < 3 {
+			return child
+		}
+		for moves, tries := 0, 0; moves < numMoves && tries < 100*numMoves; tries++ {
+			i := rng.Intn(len(edges))
+			u, v := edges[i][0], edges[i][1]
+			if rng.Intn(2) == 0 {
+				u, v = v, u
+			}
+			w := nodes[rng.Intn(len(nodes))].ID()
+			if w == u || child.HasEdgeBetween(u, w) {
+				continue
+			}
+			child.RewireEdge(u, v, w)
+			edges[i] = [2]int64{u, w}
+			moves++
+		}
+		return child
+	}
+}
+
+// Make numSwaps degree preserving double edge swaps.
+func SwapMutation(numSwaps int) Mutation {
+	return func(parent *network.AdjacencyList, rng *rand.Rand) *network.AdjacencyList {
+		return network.DegreePreservingRewire(parent, numSwaps, rng)
+	}
+}
+
+// The child gets every edge the parents share and a random half of the edges
+// only one of them has, so it has about as many edges as its parents.
+func UniformEdgeCrossover(a, b *network.AdjacencyList, rng *rand.Rand) *network.AdjacencyList {
+	child := emptyCopy(a)
+	inB := make(map[[2]int64]bool)
+	for _, e := range edgePairs(b) {
+		inB[e] = true
+	}
+	for _, e := range edgePairs(a) {
+		if inB[e] {
+			child.AddEdge(e[0], e[1])
+			delete(inB, e)
+		} else if rng.Intn(2) == 0 {
+			child.AddEdge(e[0], e[1])
+		}
+	}
+	// inB now only holds the edges that a doesn't have. They're sorted so that
+	// the same seed always picks the same ones.
+	onlyB := make([][2]int64, 0, len(inB))
+	for e := range inB {
+		onlyB = append(onlyB, e)
+	}
+	sort.Slice(onlyB, func(i, j int) bool {
+		return onlyB[i][0] < onlyB[j][0] || (onlyB[i][0] == onlyB[j][0] && onlyB[i][1] < onlyB[j][1])
+	})
+	for _, e := range onlyB {
+		if rng.Intn(2) == 0 {
+			child.AddEdge(e[0], e[1])
+		}
+	}
+	return child
+}
+
+// Split the nodes at a random ID. The child gets a's edges between nodes below the
+// split, b's edges between nodes at or above it, and the edges that cross the
+// split from a random parent.
+func SinglePointCrossover(a, b *network.AdjacencyList, rng *rand.Rand) *network.AdjacencyList {
+	child := emptyCopy(a)
+	nodes := graph.NodesOf(a.Nodes())
+	if len(nodes) == 0 {
+		return child
+	}
+	split := nodes[rng.Intn(len(nodes))].ID()
+	crossing := a
+	if rng.Intn(2) == 0 {
+		crossing = b
+	}
+	for _, e := range edgePairs(a) {
+		if e[0] < split && e[1] < split {
+			child.AddEdge(e[0], e[1])
+		}
+	}
+	for _, e := range edgePairs(b) {
+		if e[0] >= split && e[1] >= split {
+			child.AddEdge(e[0], e[1])
+		}
+	}
+	for _, e := range edgePairs(crossing) {
+		if (e[0] < split) != (e[1] < split) {
+			child.AddEdge(e[0], e[1])
+		}
+	}
+	return child
+}
+
+// Return a copy of the network without any edges.
+func emptyCopy(net *network.AdjacencyList) *network.AdjacencyList {
+	nodes := graph.NodesOf(net.Nodes())
+	adjList := make(map[int64][]graph.Node, len(nodes))
+	for _, u := range nodes {
+		adjList[u.ID()] = make([]graph.Node, 0)
+	}
+	empty := network.NewAdjacencyList(nodes, adjList)
+	for _, u := range nodes {
+		for _, attr := range net.Attributes(u.ID()) {
+			empty.AddAttribute(u.ID(), attr)
+		}
+	}
+	return empty
+}
+
+// Return the endpoints of every edge other than self loops with the smaller ID first.
+func edgePairs(net *network.AdjacencyList) [][2]int64 {
+	pairs := make([][2]int64, 0)
+	for _, e := range graph.EdgesOf(net.Edges()) {
+		u, v := e.From().ID(), e.To().ID()
+		if u != v {
+			pairs = append(pairs, [2]int64{u, v})
+		}
+	}
+	return pairs
+}
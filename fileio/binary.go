@@ -0,0 +1,441 @@
+package fileio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// The binary network format is
+//
+//	magic        "IRNB"
+//	version      uint16
+//	source hash  32 bytes, all zero unless the file caches another file
+//	records      one per network until the end of the file
+//
+// where each record is a uint32 payload length, the payload, and the CRC-32 of
+// the payload. The fixed size fields are little endian. A payload holds the
+// instance name, node IDs, edges, edge weights and node attributes, mostly as
+// varints.
+const binaryVersion = 1
+
+// The extension added to a source's path to get the path of its cache
+const BinaryCacheExtension = ".irnb"
+
+var binaryMagic = []byte("IRNB")
+
+const binaryHashLen = 32
+
+// Write the network to a file in the binary format.
+func WriteBinary(filename string, net *network.AdjacencyList) {
+	writeFileOrPanic(filename, func(w io.Writer) {
+		writeBinaryHeader(w, make([]byte, binaryHashLen))
+		writeBinaryRecord(w, "", net)
+	})
+}
+
+// Read the first network in a file in the binary format.
+func ReadBinary(filename string) *network.AdjacencyList {
+	file, err := os.Open(filename)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	if _, err := readBinaryHeader(reader); err != nil {
+		panic(err)
+	}
+	payload, err := readBinaryRecord(reader)
+	if err != nil {
+		panic(err)
+	}
+	_, net, err := decodeNetwork(payload)
+	if err != nil {
+		panic(err)
+	}
+	return net
+}
+
+// Read a network like ReadNetwork, but keep a binary copy of it next to the
+// source (filename + BinaryCacheExtension). Later calls read the copy instead of
+// parsing the source as long as the source's contents haven't changed. Failing
+// to write the cache is not an error.
+func ReadFileCached(filename string) *network.AdjacencyList {
+	contents := readFileOrPanic(filename)
+	hash := sha256Sum(contents)
+	cachePath := filename + BinaryCacheExtension
+	if source, ok := openBinarySource(cachePath, hash); ok {
+		defer source.close()
+		if _, net, ok := source.next(); ok {
+			return net
+		}
+	}
+
+	net := parseNetwork(detectFormat(filename, contents), contents)
+	if cache, ok := createBinaryCache(cachePath, hash); ok {
+		cache.write("", net)
+		cache.commit()
+	}
+	return net
+}
+
+// Iterate over a class like IterateClass, but keep a binary copy of the class
+// next to it (pathToClass + BinaryCacheExtension). The copy is written as the
+// class is read and is only kept if the iteration reaches the end of the class.
+// Later iterations read the copy as long as the class hasn't changed.
+func IterateClassCached(pathToClass string) *ClassIterator {
+	return iterateClassWithCache(pathToClass, pathToClass+BinaryCacheExtension, hashClass(pathToClass))
+}
+
+func iterateClassWithCache(pathToClass, cachePath string, hash []byte) *ClassIterator {
+	if source, ok := openBinarySource(cachePath, hash); ok {
+		return &ClassIterator{source: source}
+	}
+	var source networkSource = &parsingSource{openInstanceSource(pathToClass)}
+	if cache, ok := createBinaryCache(cachePath, hash); ok {
+		source = &cachingSource{source, cache}
+	}
+	return &ClassIterator{source: source}
+}
+
+func writeBinaryHeader(w io.Writer, hash []byte) {
+	header := make([]byte, 0, len(binaryMagic)+2+binaryHashLen)
+	header = append(header, binaryMagic...)
+	header = append(header, binaryVersion&0xff, binaryVersion>>8)
+	header = append(header, hash...)
+	if _, err := w.Write(header); err != nil {
+		panic(err)
+	}
+}
+
+// Check the magic number and version and return the source hash.
+func readBinaryHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, len(binaryMagic)+2+binaryHashLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(binaryMagic)], binaryMagic) {
+		return nil, errors.New("not a binary network file")
+	}
+	version := binary.LittleEndian.Uint16(header[len(binaryMagic):])
+	if version != binaryVersion {
+		return nil, fmt.Errorf("unsupported binary network version %d", version)
+	}
+	return header[len(binaryMagic)+2:], nil
+}
+
+func writeBinaryRecord(w io.Writer, name string, net *network.AdjacencyList) {
+	payload := encodeNetwork(name, net)
+	record := make([]byte, 4, 4+len(payload)+4)
+	binary.LittleEndian.PutUint32(record, uint32(len(payload)))
+	record = append(record, payload...)
+	record = append(record, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(record[len(record)-4:], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(record); err != nil {
+		panic(err)
+	}
+}
+
+// Return the payload of the next record or io.EOF if there are no more records.
+func readBinaryRecord(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, binary.LittleEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var checksum [4]byte
+	if _, err := io.ReadFull(r, checksum[:]); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(checksum[:]) {
+		return nil, errors.New("binary network record failed its checksum")
+	}
+	return payload, nil
+}
+
+func encodeNetwork(name string, net *network.AdjacencyList) []byte {
+	var buffer bytes.Buffer
+	putString(&buffer, name)
+
+	ids := sortedNodeIDs(net)
+	putUvarint(&buffer, uint64(len(ids)))
+	previous := int64(0)
+	for _, id := range ids {
+		putVarint(&buffer, id-previous)
+		previous = id
+	}
+
+	edges := graph.EdgesOf(net.Edges())
+	putUvarint(&buffer, uint64(len(edges)))
+	previous = 0
+	weighted := make([]graph.Edge, 0)
+	for _, e := range edges {
+		u, v := e.From().ID(), e.To().ID()
+		putVarint(&buffer, u-previous)
+		putVarint(&buffer, v-u)
+		previous = u
+		if net.EdgeWeight(u, v) != 1 {
+			weighted = append(weighted, e)
+		}
+	}
+
+	putUvarint(&buffer, uint64(len(weighted)))
+	for _, e := range weighted {
+		u, v := e.From().ID(), e.To().ID()
+		putVarint(&buffer, u)
+		putVarint(&buffer, v)
+		putFloat(&buffer, net.EdgeWeight(u, v))
+	}
+
+	// attribute keys are stored once and referred to by index
+	keys := make([]string, 0)
+	keyIndex := make(map[string]int)
+	for _, id := range ids {
+		for _, attr := range net.Attributes(id) {
+			if _, ok := keyIndex[attr.Key]; !ok {
+				keyIndex[attr.Key] = len(keys)
+				keys = append(keys, attr.Key)
+			}
+		}
+	}
+	putUvarint(&buffer, uint64(len(keys)))
+	for _, key := range keys {
+		putString(&buffer, key)
+	}
+	for _, id := range ids {
+		attrs := net.Attributes(id)
+		putUvarint(&buffer, uint64(len(attrs)))
+		for _, attr := range attrs {
+			putUvarint(&buffer, uint64(keyIndex[attr.Key]))
+			if attr.Quoted {
+				buffer.WriteByte(1)
+			} else {
+				buffer.WriteByte(0)
+			}
+			putString(&buffer, attr.Value)
+		}
+	}
+	return buffer.Bytes()
+}
+
+func decodeNetwork(payload []byte) (name string, net *network.AdjacencyList, err error) {
+	// the readers panic on malformed data to keep the decoding below readable
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed binary network: %v", r)
+		}
+	}()
+	r := bytes.NewReader(payload)
+	name = getString(r)
+
+	nodes := make([]graph.Node, getUvarint(r))
+	id := int64(0)
+	for i := range nodes {
+		id += getVarint(r)
+		nodes[i] = network.NewVertex(id)
+	}
+
+	edges := make([]graph.Edge, getUvarint(r))
+	u := int64(0)
+	for i := range edges {
+		u += getVarint(r)
+		v := u + getVarint(r)
+		edges[i] = network.NewLink(network.NewVertex(u), network.NewVertex(v))
+	}
+
+	numWeights := getUvarint(r)
+	weights := make(map[[2]int64]float64)
+	for i := uint64(0); i < numWeights; i++ {
+		u := getVarint(r)
+		v := getVarint(r)
+		weights[[2]int64{u, v}] = getFloat(r)
+	}
+	for i, e := range edges {
+		if weight, ok := weights[[2]int64{e.From().ID(), e.To().ID()}]; ok {
+			edges[i] = network.NewWeightedLink(e.From(), e.To(), weight)
+		}
+	}
+
+	keys := make([]string, getUvarint(r))
+	for i := range keys {
+		keys[i] = getString(r)
+	}
+	attributes := make(map[int64][]network.Attribute)
+	for _, node := range nodes {
+		numAttrs := getUvarint(r)
+		for i := uint64(0); i < numAttrs; i++ {
+			key := keys[getUvarint(r)]
+			quoted, err := r.ReadByte()
+			if err != nil {
+				panic(err)
+			}
+			attributes[node.ID()] = append(attributes[node.ID()],
+				network.Attribute{Key: key, Value: getString(r), Quoted: quoted == 1})
+		}
+	}
+	return name, newNetwork(nodes, edges, attributes), nil
+}
+
+func putUvarint(buffer *bytes.Buffer, x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buffer.Write(b[:binary.PutUvarint(b[:], x)])
+}
+
+func putVarint(buffer *bytes.Buffer, x int64) {
+	var b [binary.MaxVarintLen64]byte
+	buffer.Write(b[:binary.PutVarint(b[:], x)])
+}
+
+func putString(buffer *bytes.Buffer, s string) {
+	putUvarint(buffer, uint64(len(s)))
+	buffer.WriteString(s)
+}
+
+func putFloat(buffer *bytes.Buffer, x float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(x))
+	buffer.Write(b[:])
+}
+
+func getUvarint(r *bytes.Reader) uint64 {
+	x, err := binary.ReadUvarint(r)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func getVarint(r *bytes.Reader) int64 {
+	x, err := binary.ReadVarint(r)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func getString(r *bytes.Reader) string {
+	length := getUvarint(r)
+	if length > uint64(r.Len()) {
+		panic(io.ErrUnexpectedEOF)
+	}
+	b := make([]byte, length)
+	io.ReadFull(r, b)
+	return string(b)
+}
+
+func getFloat(r *bytes.Reader) float64 {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		panic(err)
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+}
+
+// Reads networks from a binary file one record at a time
+type binarySource struct {
+	file   *os.File
+	reader *bufio.Reader
+}
+
+// Open a binary file if it exists, was made from a source with the given hash,
+// and every record passes its checksum.
+func openBinarySource(path string, hash []byte) (*binarySource, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	reader := bufio.NewReader(file)
+	if !isValidBinary(reader, hash) {
+		file.Close()
+		return nil, false
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, false
+	}
+	reader.Reset(file)
+	readBinaryHeader(reader)
+	return &binarySource{file, reader}, true
+}
+
+func isValidBinary(reader *bufio.Reader, hash []byte) bool {
+	sourceHash, err := readBinaryHeader(reader)
+	if err != nil || !bytes.Equal(sourceHash, hash) {
+		return false
+	}
+	for {
+		_, err := readBinaryRecord(reader)
+		if errors.Is(err, io.EOF) {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+func (s *binarySource) next() (string, *network.AdjacencyList, bool) {
+	payload, err := readBinaryRecord(s.reader)
+	if errors.Is(err, io.EOF) {
+		return "", nil, false
+	} else if err != nil {
+		panic(err)
+	}
+	name, net, err := decodeNetwork(payload)
+	if err != nil {
+		panic(err)
+	}
+	return name, net, true
+}
+
+func (s *binarySource) close() {
+	s.file.Close()
+}
+
+// Writes a binary cache to a temporary file that only replaces the real cache
+// once commit is called so that readers never see a partial cache.
+type binaryCache struct {
+	path   string
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func createBinaryCache(path string, hash []byte) (*binaryCache, bool) {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, false
+	}
+	cache := &binaryCache{path, file, bufio.NewWriter(file)}
+	writeBinaryHeader(cache.writer, hash)
+	return cache, true
+}
+
+func (c *binaryCache) write(name string, net *network.AdjacencyList) {
+	writeBinaryRecord(c.writer, name, net)
+}
+
+func (c *binaryCache) commit() {
+	flushErr := c.writer.Flush()
+	closeErr := c.file.Close()
+	if flushErr != nil || closeErr != nil || os.Rename(c.file.Name(), c.path) != nil {
+		os.Remove(c.file.Name())
+	}
+}
+
+func (c *binaryCache) discard() {
+	c.file.Close()
+	os.Remove(c.file.Name())
+}
@@ -0,0 +1,76 @@
+package fileio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+// Read a class like ReadClass, but keep the parsed networks in cacheDir in the
+// binary format so that later reads of the same class skip parsing. Cache files
+// are named after a hash of the class's contents, so a class that changes gets
+// a new cache file instead of reusing a stale one. An unreadable cache file is
+// ignored and rewritten.
+func ReadClassCached(pathToClass, cacheDir string) []*network.AdjacencyList {
+	if err := os.MkdirAll(cacheDir, fs.ModePerm); err != nil {
+		panic(err)
+	}
+	hash := hashClass(pathToClass)
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(hash)+BinaryCacheExtension)
+	return readSortedInstances(iterateClassWithCache(pathToClass, cachePath, hash))
+}
+
+// Return a SHA-256 hash of the class. Directories are hashed using the relative
+// path and contents of every file in them.
+func hashClass(pathToClass string) []byte {
+	hash := sha256.New()
+	info, err := os.Stat(pathToClass)
+	if err != nil {
+		panic(err)
+	}
+	if !info.IsDir() {
+		hashFile(hash, pathToClass)
+		return hash.Sum(nil)
+	}
+
+	paths := make([]string, 0)
+	err = filepath.WalkDir(pathToClass, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			paths = append(paths, path)
+		}
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		relPath, _ := filepath.Rel(pathToClass, path)
+		io.WriteString(hash, relPath)
+		hash.Write([]byte{0})
+		hashFile(hash, path)
+	}
+	return hash.Sum(nil)
+}
+
+func hashFile(w io.Writer, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(w, file); err != nil {
+		panic(err)
+	}
+}
+
+func sha256Sum(contents []byte) []byte {
+	sum := sha256.Sum256(contents)
+	return sum[:]
+}
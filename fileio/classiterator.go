@@ -0,0 +1,298 @@
+package fileio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+// A network from a class along with where it came from
+type ClassInstance struct {
+	// the position of the instance in the order it was read
+	Index int
+	// the number in the instance's file name (instance-<ID>.txt)
+	ID int
+	// the base name of the file the instance was read from
+	Name string
+	Net  *network.AdjacencyList
+}
+
+// Reads the instances of a class one at a time so that only the current
+// instance needs to be in memory. Instances are produced in the order they are
+// stored in the archive, which may not be sorted by ID.
+//
+//	it := IterateClass(pathToClass)
+//	defer it.Close()
+//	for it.Next() {
+//		instance := it.Instance()
+//		...
+//	}
+type ClassIterator struct {
+	source  networkSource
+	current ClassInstance
+	index   int
+}
+
+// Start iterating over the class at pathToClass, which may be anything ReadClass accepts.
+func IterateClass(pathToClass string) *ClassIterator {
+	return &ClassIterator{source: &parsingSource{openInstanceSource(pathToClass)}}
+}
+
+// Read the next instance. Return false once there are no more instances.
+func (it *ClassIterator) Next() bool {
+	name, net, ok := it.source.next()
+	if !ok {
+		return false
+	}
+	it.current = ClassInstance{
+		Index: it.index,
+		ID:    atoiOrPanic(idMatcher.FindString(name)),
+		Name:  name,
+		Net:   net,
+	}
+	it.index++
+	return true
+}
+
+// Return the instance read by the last call to Next.
+func (it *ClassIterator) Instance() ClassInstance {
+	return it.current
+}
+
+// Release the file backing the iterator.
+func (it *ClassIterator) Close() {
+	it.source.close()
+}
+
+// Send each network in the class through the returned channel, which is closed
+// after the last network. The iterator is closed once it is exhausted. Reading
+// only starts as the channel is drained, so at most one unconsumed network is
+// held in memory.
+func (it *ClassIterator) Networks() <-chan *network.AdjacencyList {
+	nets := make(chan *network.AdjacencyList)
+	go func() {
+		defer close(nets)
+		defer it.Close()
+		for it.Next() {
+			nets <- it.Instance().Net
+		}
+	}()
+	return nets
+}
+
+// A collection of networks that can be read one at a time
+type networkSource interface {
+	// return the name of the next network's file and the network
+	next() (name string, net *network.AdjacencyList, ok bool)
+	close()
+}
+
+// Parses the files from an instanceSource
+type parsingSource struct {
+	files instanceSource
+}
+
+func (s *parsingSource) next() (string, *network.AdjacencyList, bool) {
+	name, contents, ok := s.files.next()
+	if !ok {
+		return "", nil, false
+	}
+	return name, parseNetwork(detectFormat(name, contents), contents), true
+}
+
+func (s *parsingSource) close() {
+	s.files.close()
+}
+
+// Writes every network from source to a binary cache. The cache is committed
+// once source runs out and discarded if the source is closed before that.
+type cachingSource struct {
+	source networkSource
+	cache  *binaryCache
+}
+
+func (s *cachingSource) next() (string, *network.AdjacencyList, bool) {
+	name, net, ok := s.source.next()
+	if s.cache == nil {
+		return name, net, ok
+	}
+	if ok {
+		s.cache.write(name, net)
+	} else {
+		s.cache.commit()
+		s.cache = nil
+	}
+	return name, net, ok
+}
+
+func (s *cachingSource) close() {
+	if s.cache != nil {
+		s.cache.discard()
+		s.cache = nil
+	}
+	s.source.close()
+}
+
+// A collection of instance files that can be read one at a time
+type instanceSource interface {
+	// return the base name and contents of the next instance file
+	next() (name string, contents []byte, ok bool)
+	close()
+}
+
+func openInstanceSource(pathToClass string) instanceSource {
+	info, err := os.Stat(pathToClass)
+	if err != nil {
+		panic(err)
+	}
+	lowerPath := strings.ToLower(pathToClass)
+	switch {
+	case info.IsDir():
+		return newDirSource(pathToClass)
+	case strings.HasSuffix(lowerPath, ".zip"):
+		return newZipSource(pathToClass)
+	case strings.HasSuffix(lowerPath, ".tar"):
+		return newTarSource(pathToClass, false)
+	default:
+		return newTarSource(pathToClass, true)
+	}
+}
+
+type tarSource struct {
+	file       *os.File
+	gzipReader *gzip.Reader
+	tarReader  *tar.Reader
+}
+
+func newTarSource(pathToTarball string, isGzipped bool) *tarSource {
+	file, err := os.Open(pathToTarball)
+	if err != nil {
+		panic(err)
+	}
+	source := &tarSource{file: file}
+	var reader io.Reader = file
+	if isGzipped {
+		source.gzipReader, err = gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			panic(err)
+		}
+		reader = source.gzipReader
+	}
+	source.tarReader = tar.NewReader(reader)
+	return source
+}
+
+func (s *tarSource) next() (string, []byte, bool) {
+	for {
+		header, err := s.tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return "", nil, false
+		} else if err != nil {
+			panic(err)
+		}
+
+		name := filepath.Base(header.Name)
+		if header.FileInfo().IsDir() || !instanceMatcher.MatchString(name) {
+			continue
+		}
+		contents, err := ioutil.ReadAll(s.tarReader)
+		if err != nil {
+			panic(err)
+		}
+		return name, contents, true
+	}
+}
+
+func (s *tarSource) close() {
+	if s.gzipReader != nil {
+		s.gzipReader.Close()
+	}
+	s.file.Close()
+}
+
+type zipSource struct {
+	zipReader *zip.ReadCloser
+	nextFile  int
+}
+
+func newZipSource(pathToZip string) *zipSource {
+	zipReader, err := zip.OpenReader(pathToZip)
+	if err != nil {
+		panic(err)
+	}
+	return &zipSource{zipReader: zipReader}
+}
+
+func (s *zipSource) next() (string, []byte, bool) {
+	for s.nextFile < len(s.zipReader.File) {
+		zipFile := s.zipReader.File[s.nextFile]
+		s.nextFile++
+		name := filepath.Base(zipFile.Name)
+		if zipFile.FileInfo().IsDir() || !instanceMatcher.MatchString(name) {
+			continue
+		}
+		return name, readZipFile(zipFile), true
+	}
+	return "", nil, false
+}
+
+func (s *zipSource) close() {
+	s.zipReader.Close()
+}
+
+func readZipFile(zipFile *zip.File) []byte {
+	file, err := zipFile.Open()
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		panic(err)
+	}
+	return contents
+}
+
+// Only the paths are collected up front. Each file is read when it is reached.
+type dirSource struct {
+	paths    []string
+	nextPath int
+}
+
+func newDirSource(dir string) *dirSource {
+	paths := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && instanceMatcher.MatchString(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &dirSource{paths: paths}
+}
+
+func (s *dirSource) next() (string, []byte, bool) {
+	if s.nextPath >= len(s.paths) {
+		return "", nil, false
+	}
+	path := s.paths[s.nextPath]
+	s.nextPath++
+	return filepath.Base(path), readFileOrPanic(path), true
+}
+
+func (s *dirSource) close() {}
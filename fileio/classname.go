@@ -0,0 +1,89 @@
+package fileio
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/notation"
+)
+
+// The type of a parameter parsed from a class name
+type ParamKind = notation.Kind
+
+const (
+	IntParam    = notation.IntValue
+	FloatParam  = notation.FloatValue
+	TupleParam  = notation.TupleValue
+	StringParam = notation.StringValue
+)
+
+// A generator parameter from a class name such as the ib=(5, 10) in
+// ConnComm(N_comm=10,ib=(5, 10),num_comms=50,ob=(3, 6))
+type ClassParam = notation.Value
+
+// The model and parameters that generated a class of networks
+type ClassMetadata struct {
+	Model  string
+	Params []ClassParam
+}
+
+// Parse a class name of the form Model(name=value,name=value,...). Values can be
+// ints, floats, tuples of values in parentheses, or anything else, which is kept
+// as a string. Directories and extensions such as .tar.gz are stripped first, so
+// a path to a class can be passed directly.
+func ParseClassName(className string) (ClassMetadata, error) {
+	name := stripClassExtension(filepath.Base(className))
+	call, err := notation.Parse(name)
+	if err != nil {
+		return ClassMetadata{}, fmt.Errorf("class name %s: %v", name, err)
+	}
+	return ClassMetadata{Model: call.Name, Params: call.Args}, nil
+}
+
+// Return the parameter with the given name.
+func (m ClassMetadata) Param(name string) (ClassParam, bool) {
+	for _, param := range m.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return ClassParam{}, false
+}
+
+// Return one column name and value for each scalar parameter. Tuples are split
+// into one column per element named name_0, name_1, etc.
+func (m ClassMetadata) Columns() (names []string, values []string) {
+	names = make([]string, 0)
+	values = make([]string, 0)
+	for _, param := range m.Params {
+		n, v := paramColumns(param, param.Name)
+		names = append(names, n...)
+		values = append(values, v...)
+	}
+	return names, values
+}
+
+func paramColumns(p ClassParam, name string) ([]string, []string) {
+	if p.Kind != TupleParam {
+		return []string{name}, []string{p.Raw}
+	}
+	names := make([]string, 0)
+	values := make([]string, 0)
+	for i, element := range p.Tuple {
+		n, v := paramColumns(element, fmt.Sprintf("%s_%d", name, i))
+		names = append(names, n...)
+		values = append(values, v...)
+	}
+	return names, values
+}
+
+// Remove the extension of a class file, including both parts of .tar.gz.
+func stripClassExtension(name string) string {
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip", BinaryCacheExtension} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
@@ -0,0 +1,299 @@
+package fileio
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// A file format that a network can be read from
+type Format int
+
+const (
+	UnknownFormat Format = iota
+	GML
+	EdgeList
+	AdjacencyMatrix
+	MatrixMarket
+	Pajek
+	GraphML
+	NPY
+)
+
+func (f Format) String() string {
+	switch f {
+	case GML:
+		return "GML"
+	case EdgeList:
+		return "edge list"
+	case AdjacencyMatrix:
+		return "adjacency matrix"
+	case MatrixMarket:
+		return "Matrix Market"
+	case Pajek:
+		return "Pajek"
+	case GraphML:
+		return "GraphML"
+	case NPY:
+		return "NPY"
+	}
+	return "unknown"
+}
+
+// Read a network from a file in any of the supported formats. The format is
+// determined by DetectFormat.
+func ReadNetwork(filename string) *network.AdjacencyList {
+	contents := readFileOrPanic(filename)
+	return parseNetwork(detectFormat(filename, contents), contents)
+}
+
+// Determine the format of the file from its extension, falling back on its
+// contents for ambiguous extensions such as .txt (which this project uses for GML).
+func DetectFormat(filename string) Format {
+	return detectFormat(filename, readFileOrPanic(filename))
+}
+
+func detectFormat(filename string, contents []byte) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gml":
+		return GML
+	case ".graphml":
+		return GraphML
+	case ".net", ".paj":
+		return Pajek
+	case ".mtx", ".mm":
+		return MatrixMarket
+	case ".npy":
+		return NPY
+	case ".edges", ".edgelist", ".el":
+		return EdgeList
+	case ".adj":
+		return AdjacencyMatrix
+	}
+	return sniffFormat(contents)
+}
+
+// Guess the format of a file from its first few bytes.
+func sniffFormat(contents []byte) Format {
+	if bytes.HasPrefix(contents, npyMagic) {
+		return NPY
+	}
+	trimmed := bytes.TrimSpace(contents)
+	if bytes.HasPrefix(trimmed, []byte("%%MatrixMarket")) {
+		return MatrixMarket
+	}
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return GraphML
+	}
+	if bytes.HasPrefix(trimmed, []byte("*")) {
+		return Pajek
+	}
+	if fields := strings.Fields(string(firstLine(trimmed))); len(fields) > 0 &&
+		(fields[0] == "graph" || strings.HasPrefix(fields[0], "graph[")) {
+		return GML
+	}
+	if len(trimmed) == 0 {
+		return UnknownFormat
+	}
+	if looksLikeMatrix(trimmed) {
+		return AdjacencyMatrix
+	}
+	return EdgeList
+}
+
+func firstLine(contents []byte) []byte {
+	if i := bytes.IndexByte(contents, '\n'); i >= 0 {
+		return contents[:i]
+	}
+	return contents
+}
+
+// A symmetric square grid of numbers is treated as an adjacency matrix. Anything
+// else with numbers on each line is an edge list.
+func looksLikeMatrix(contents []byte) bool {
+	rows := dataLines(string(contents))
+	// a single line is more likely to be an edge or an isolated node
+	if len(rows) < 2 {
+		return false
+	}
+	entries := make([][]string, len(rows))
+	for i, row := range rows {
+		entries[i] = splitRow(row)
+		if len(entries[i]) != len(rows) {
+			return false
+		}
+	}
+	for i := range entries {
+		for j := range entries[i] {
+			if _, err := strconv.ParseFloat(entries[i][j], 64); err != nil || entries[i][j] != entries[j][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func parseNetwork(format Format, contents []byte) *network.AdjacencyList {
+	return parseNetworkRaw(format, contents).build()
+}
+
+func parseNetworkRaw(format Format, contents []byte) *rawNetwork {
+	switch format {
+	case GML:
+		return parseGMLRaw(contents)
+	case EdgeList:
+		return parsePlainEdgeList(contents)
+	case AdjacencyMatrix:
+		return parseAdjacencyMatrix(contents)
+	case MatrixMarket:
+		return parseMatrixMarket(contents)
+	case Pajek:
+		return parsePajek(contents)
+	case GraphML:
+		return parseGraphML(contents)
+	case NPY:
+		return parseNPY(contents)
+	}
+	panic("unable to determine the format of the network")
+}
+
+// Build a network out of nodes and edges that were read from a file. Nodes are
+// sorted by ID and edges are added in both directions. Edges that implement
+// graph.WeightedEdge keep their weights.
+func newNetwork(nodes []graph.Node, edges []graph.Edge, attributes map[int64][]network.Attribute) *network.AdjacencyList {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+
+	adjList := make(map[int64][]graph.Node)
+	for _, u := range nodes {
+		adjList[u.ID()] = make([]graph.Node, 0)
+	}
+	for _, e := range edges {
+		u := e.From()
+		v := e.To()
+		adjList[u.ID()] = append(adjList[u.ID()], v)
+		adjList[v.ID()] = append(adjList[v.ID()], u)
+	}
+	net := network.NewAdjacencyList(nodes, adjList)
+	for _, e := range edges {
+		if weighted, ok := e.(graph.WeightedEdge); ok && weighted.Weight() != 1 {
+			net.SetEdgeWeight(e.From().ID(), e.To().ID(), weighted.Weight())
+		}
+	}
+	for id, attrs := range attributes {
+		for _, attr := range attrs {
+			net.AddAttribute(id, attr)
+		}
+	}
+	return net
+}
+
+// Assigns IDs to nodes that are named in a file. Names that are integers are used
+// as IDs directly if they are exactly 0 to N-1. Otherwise, such as when the file
+// numbers its nodes from 1 or a name isn't an integer, every node is numbered in
+// the order it first appeared and keeps its name as a label attribute. Call add
+// for every name before calling anything else.
+type nodeNamer struct {
+	names []string
+	ids   map[string]int64
+	// the IDs the names would have if they were used as IDs
+	numericIDs map[int64]bool
+	numerics   bool
+	maxID      int64
+}
+
+func newNodeNamer() *nodeNamer {
+	return &nodeNamer{
+		names:      make([]string, 0),
+		ids:        make(map[string]int64),
+		numericIDs: make(map[int64]bool),
+		numerics:   true,
+		maxID:      -1,
+	}
+}
+
+func (n *nodeNamer) add(name string) {
+	if _, ok := n.ids[name]; ok {
+		return
+	}
+	n.ids[name] = int64(len(n.names))
+	n.names = append(n.names, name)
+	id, err := strconv.ParseInt(name, 10, 64)
+	// names like 1 and 01 would get the same ID
+	if err != nil || id < 0 || n.numericIDs[id] {
+		n.numerics = false
+		return
+	}
+	n.numericIDs[id] = true
+	if id > n.maxID {
+		n.maxID = id
+	}
+}
+
+// Return true if the names are used as IDs, which is only the case when they
+// are distinct integers from 0 to N-1.
+func (n *nodeNamer) namesAreIDs() bool {
+	return n.numerics && n.maxID < int64(len(n.names))
+}
+
+func (n *nodeNamer) id(name string) int64 {
+	if n.namesAreIDs() {
+		id, _ := strconv.ParseInt(name, 10, 64)
+		return id
+	}
+	return n.ids[name]
+}
+
+func (n *nodeNamer) nodes() []graph.Node {
+	nodes := make([]graph.Node, len(n.names))
+	for i, name := range n.names {
+		nodes[i] = network.NewVertex(n.id(name))
+	}
+	return nodes
+}
+
+// Return a label attribute for each node if the names were replaced by IDs.
+func (n *nodeNamer) attributes() map[int64][]network.Attribute {
+	attributes := make(map[int64][]network.Attribute)
+	if n.namesAreIDs() {
+		return attributes
+	}
+	for _, name := range n.names {
+		id := n.id(name)
+		attributes[id] = []network.Attribute{{Key: "label", Value: name, Quoted: true}}
+	}
+	return attributes
+}
+
+// Return the lines that aren't blank or comments (starting with # or %).
+func dataLines(contents string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '%' {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Split a line on whitespace and commas.
+func splitRow(line string) []string {
+	return strings.FieldsFunc(line, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\r'
+	})
+}
+
+func readFileOrPanic(filename string) []byte {
+	fileContents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		panic(err)
+	}
+	return fileContents
+}
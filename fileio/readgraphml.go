@@ -0,0 +1,153 @@
+package fileio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+type graphMLFile struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr,omitempty"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr"`
+	Name    string `xml:"attr.name,attr"`
+	Type    string `xml:"attr.type,attr"`
+	Default string `xml:"default,omitempty"`
+}
+
+type graphMLGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr,omitempty"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Read a GraphML file. Node data become attributes named after their keys. Edge
+// data are ignored except for weights, which are read from a key named weight.
+// Nodes that edges name without declaring are added without data.
+func ReadGraphML(filename string) *network.AdjacencyList {
+	return parseGraphML(readFileOrPanic(filename)).build()
+}
+
+func parseGraphML(contents []byte) *rawNetwork {
+	var file graphMLFile
+	if err := xml.Unmarshal(contents, &file); err != nil {
+		panic(err)
+	}
+
+	namer := newNodeNamer()
+	for _, node := range file.Graph.Nodes {
+		namer.add(node.ID)
+	}
+	// edges may name nodes that weren't declared
+	for _, edge := range file.Graph.Edges {
+		namer.add(edge.Source)
+		namer.add(edge.Target)
+	}
+
+	nodeKeys := make(map[string]graphMLKey)
+	var weightKey *graphMLKey
+	for i, key := range file.Keys {
+		if (key.For == "edge" || key.For == "all") && key.Name == "weight" {
+			weightKey = &file.Keys[i]
+		}
+		if key.For == "node" || key.For == "all" {
+			if key.Name == "" {
+				key.Name = key.ID
+			}
+			nodeKeys[key.ID] = key
+		}
+	}
+
+	attributes := namer.attributes()
+	for _, node := range file.Graph.Nodes {
+		id := namer.id(node.ID)
+		hasKey := make(map[string]bool)
+		for _, data := range node.Data {
+			key, ok := nodeKeys[data.Key]
+			if !ok {
+				continue
+			}
+			hasKey[key.ID] = true
+			attributes[id] = append(attributes[id], graphMLAttribute(key, data.Value))
+		}
+		// fill in the defaults in the order the keys were declared
+		for _, key := range file.Keys {
+			if key, ok := nodeKeys[key.ID]; ok && !hasKey[key.ID] && key.Default != "" {
+				attributes[id] = append(attributes[id], graphMLAttribute(key, key.Default))
+			}
+		}
+	}
+
+	// only the nodes in the file are declared so that validation can tell which
+	// nodes were made up for edges
+	nodes := make([]graph.Node, len(file.Graph.Nodes))
+	for i, node := range file.Graph.Nodes {
+		nodes[i] = network.NewVertex(namer.id(node.ID))
+	}
+	edges := make([]graph.Edge, len(file.Graph.Edges))
+	for i, edge := range file.Graph.Edges {
+		u := network.NewVertex(namer.id(edge.Source))
+		v := network.NewVertex(namer.id(edge.Target))
+		edges[i] = network.NewWeightedLink(u, v, edgeWeight(weightKey, edge))
+	}
+	raw := newRawNetworkFrom(nodes, edges, attributes)
+	raw.declareEndpoints = true
+	raw.endpointAttrs = attributes
+	return raw
+}
+
+// Return the weight of the edge, which is 1 if there isn't a weight key.
+func edgeWeight(weightKey *graphMLKey, edge graphMLEdge) float64 {
+	if weightKey == nil {
+		return 1
+	}
+	value := weightKey.Default
+	for _, data := range edge.Data {
+		if data.Key == weightKey.ID {
+			value = data.Value
+		}
+	}
+	if strings.TrimSpace(value) == "" {
+		return 1
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		panic(fmt.Errorf("parsing error: edge %s-%s has a weight of %s, which isn't a number",
+			edge.Source, edge.Target, value))
+	}
+	return weight
+}
+
+func graphMLAttribute(key graphMLKey, value string) network.Attribute {
+	return network.Attribute{
+		Key:    key.Name,
+		Value:  strings.TrimSpace(value),
+		Quoted: key.Type == "string" || key.Type == "",
+	}
+}
@@ -0,0 +1,97 @@
+package fileio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Read a Matrix Market (.mtx) file holding a square adjacency matrix in either
+// coordinate or array format. Any nonzero entry is an edge weighted by the entry,
+// and every edge of a pattern matrix has a weight of 1.
+func ReadMatrixMarket(filename string) *network.AdjacencyList {
+	return parseMatrixMarket(readFileOrPanic(filename)).build()
+}
+
+func parseMatrixMarket(contents []byte) *rawNetwork {
+	lines := strings.Split(string(contents), "\n")
+	header := strings.Fields(strings.ToLower(lines[0]))
+	if len(header) < 5 || header[0] != "%%matrixmarket" || header[1] != "matrix" {
+		panic(fmt.Errorf("parsing error: bad Matrix Market header '%s'", strings.TrimSpace(lines[0])))
+	}
+	layout, field, symmetry := header[2], header[3], header[4]
+	// dataLines skips the header because it starts with %
+	rows := dataLines(string(contents))
+	if len(rows) == 0 {
+		panic(fmt.Errorf("parsing error: Matrix Market file is missing its size line"))
+	}
+	size := strings.Fields(rows[0])
+	numRows := atoiOrPanic(size[0])
+	numCols := atoiOrPanic(size[1])
+	if numRows != numCols {
+		panic(fmt.Errorf("parsing error: adjacency matrix must be square, got %dx%d", numRows, numCols))
+	}
+	N := numRows
+	rows = rows[1:]
+
+	switch layout {
+	case "coordinate":
+		nodes := make([]graph.Node, N)
+		for i := range nodes {
+			nodes[i] = network.NewVertex(int64(i))
+		}
+		edges := make([]graph.Edge, 0, len(rows))
+		hasEdge := make(map[[2]int]bool)
+		for _, row := range rows {
+			fields := strings.Fields(row)
+			// Matrix Market indices start at 1
+			i := atoiOrPanic(fields[0]) - 1
+			j := atoiOrPanic(fields[1]) - 1
+			weight := 1.0
+			if field != "pattern" {
+				weight = parseFloatOrPanic(fields[2])
+			}
+			if weight == 0 {
+				continue
+			}
+			if i > j {
+				i, j = j, i
+			}
+			// general matrices store both directions of an undirected edge
+			if hasEdge[[2]int{i, j}] {
+				continue
+			}
+			hasEdge[[2]int{i, j}] = true
+			edges = append(edges, network.NewWeightedLink(nodes[i], nodes[j], weight))
+		}
+		return newRawNetworkFrom(nodes, edges, make(map[int64][]network.Attribute))
+	case "array":
+		entries := make([]float64, N*N)
+		k := 0
+		// entries are stored in column major order and symmetric matrices only
+		// store the lower triangle
+		for j := 0; j < N; j++ {
+			start := 0
+			if symmetry != "general" {
+				start = j
+			}
+			for i := start; i < N; i++ {
+				entries[i*N+j] = parseFloatOrPanic(strings.Fields(rows[k])[0])
+				k++
+			}
+		}
+		return rawFromMatrix(N, func(i, j int) float64 { return entries[i*N+j] })
+	}
+	panic(fmt.Errorf("parsing error: unsupported Matrix Market layout '%s'", layout))
+}
+
+func parseFloatOrPanic(str string) float64 {
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
@@ -2,8 +2,6 @@ package fileio
 
 import (
 	"fmt"
-	"io/ioutil"
-	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -12,28 +10,18 @@ import (
 	"gonum.org/v1/gonum/graph"
 )
 
-// Read a file in GraphML
+// Read a file in GML
 func ReadFile(filename string) *network.AdjacencyList {
-	fileContents, err := ioutil.ReadFile(filename)
-	if err != nil {
-		panic(err)
-	}
-	tokens := tokenizeGMLString(string(fileContents))
-	nodes, edges := parseGraph(tokens)
-	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	return parseGML(readFileOrPanic(filename))
+}
 
-	adjList := make(map[int64][]graph.Node)
-	for _, u := range nodes {
-		adjList[u.ID()] = make([]graph.Node, 0)
-	}
-	for _, e := range edges {
-		u := e.From()
-		v := e.To()
-		adjList[u.ID()] = append(adjList[u.ID()], v)
-		adjList[v.ID()] = append(adjList[v.ID()], u)
-	}
-	net := network.NewAdjacencyList(nodes, adjList)
-	return net
+func parseGML(contents []byte) *network.AdjacencyList {
+	return parseGMLRaw(contents).build()
+}
+
+func parseGMLRaw(contents []byte) *rawNetwork {
+	tokens := tokenizeGMLString(string(contents))
+	return parseGraph(tokens)
 }
 
 func tokenizeGMLString(gml string) []token {
@@ -41,45 +29,55 @@ func tokenizeGMLString(gml string) []token {
 	var sBuilder strings.Builder
 	lineNum := 1
 	columnNum := 0
+	inQuotes := false
 	for _, c := range gml {
 		columnNum++
-		if !unicode.IsSpace(c) {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if inQuotes || !unicode.IsSpace(c) {
 			sBuilder.WriteRune(c)
 		} else if sBuilder.Len() > 0 {
 			content := sBuilder.String()
 			newToken := token{content, lineNum, columnNum - len(content)}
 			tokens = append(tokens, newToken)
 			sBuilder.Reset()
-			if c == '\n' {
-				lineNum++
-				columnNum = 0
-			}
 		}
+		if c == '\n' {
+			lineNum++
+			columnNum = 0
+		}
+	}
+	if sBuilder.Len() > 0 {
+		content := sBuilder.String()
+		tokens = append(tokens, token{content, lineNum, columnNum - len(content) + 1})
 	}
 	return tokens
 }
 
-// return the nodes and the edges
-func parseGraph(tokens []token) ([]graph.Node, []graph.Edge) {
+// return the nodes, their attributes, and the edges along with where they were found
+func parseGraph(tokens []token) *rawNetwork {
+	raw := newRawNetwork()
 	tokens = match(tokens, "graph")
 	tokens = match(tokens, "[")
-	nodes, tokens := parseNodeList(tokens)
-	edges, tokens := parseEdgeList(tokens)
+	tokens = parseNodeList(tokens, raw)
+	tokens = parseEdgeList(tokens, raw)
 	match(tokens, "]")
-	return nodes, edges
+	return raw
 }
 
-func parseNodeList(tokens []token) ([]graph.Node, []token) {
-	nodes := make([]graph.Node, 0)
-	var u graph.Node
-	for tokens[0].content != "edge" {
-		u, tokens = parseNode(tokens)
-		nodes = append(nodes, u)
+func parseNodeList(tokens []token, raw *rawNetwork) []token {
+	for tokens[0].content != "edge" && tokens[0].content != "]" {
+		start := tokens[0]
+		var u graph.Node
+		var attrs []network.Attribute
+		u, attrs, tokens = parseNode(tokens)
+		raw.addNode(u, attrs, location{start.line, start.column})
 	}
-	return nodes, tokens
+	return tokens
 }
 
-func parseNode(tokens []token) (graph.Node, []token) {
+func parseNode(tokens []token) (graph.Node, []network.Attribute, []token) {
 	tokens = match(tokens, "node")
 	tokens = match(tokens, "[")
 	tokens = match(tokens, "id")
@@ -88,22 +86,33 @@ func parseNode(tokens []token) (graph.Node, []token) {
 	if err != nil {
 		panic(err)
 	}
-	// skip the rest of the data
+	// the rest of the data are key value pairs
+	attrs := make([]network.Attribute, 0)
 	for tokens[0].content != "]" {
-		tokens = tokens[1:]
+		attrs = append(attrs, parseAttribute(tokens[0], tokens[1]))
+		tokens = tokens[2:]
 	}
 	tokens = match(tokens, "]")
-	return network.NewVertex(int64(u)), tokens
+	return network.NewVertex(int64(u)), attrs, tokens
 }
 
-func parseEdgeList(tokens []token) ([]graph.Edge, []token) {
-	edges := make([]graph.Edge, 0)
-	var e graph.Edge
+func parseAttribute(key, value token) network.Attribute {
+	content := value.content
+	quoted := len(content) >= 2 && content[0] == '"' && content[len(content)-1] == '"'
+	if quoted {
+		content = gmlUnescaper.Replace(content[1 : len(content)-1])
+	}
+	return network.Attribute{Key: key.content, Value: content, Quoted: quoted}
+}
+
+func parseEdgeList(tokens []token, raw *rawNetwork) []token {
 	for tokens[0].content != "]" {
+		start := tokens[0]
+		var e graph.Edge
 		e, tokens = parseEdge(tokens)
-		edges = append(edges, e)
+		raw.addEdge(e, location{start.line, start.column})
 	}
-	return edges, tokens
+	return tokens
 }
 
 func parseEdge(tokens []token) (graph.Edge, []token) {
@@ -124,13 +133,20 @@ func parseEdge(tokens []token) (graph.Edge, []token) {
 		panic(err)
 	}
 
-	// skip the rest of the data
+	// keep the weight and skip the rest of the data
+	weight := 1.0
 	for tokens[0].content != "]" {
-		tokens = tokens[1:]
+		if tokens[0].content == "weight" {
+			weight, err = strconv.ParseFloat(tokens[1].content, 64)
+			if err != nil {
+				panic(err)
+			}
+		}
+		tokens = tokens[2:]
 	}
 	tokens = match(tokens, "]")
 
-	e := network.NewLink(network.NewVertex(int64(u)), network.NewVertex(int64(v)))
+	e := network.NewWeightedLink(network.NewVertex(int64(u)), network.NewVertex(int64(v)), weight)
 	return e, tokens
 }
 
@@ -0,0 +1,107 @@
+package fileio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+var npyMagic = []byte("\x93NUMPY")
+
+var (
+	npyDescrMatcher   = regexp.MustCompile(`'descr'\s*:\s*'([<>|=]?)([biuf])(\d+)'`)
+	npyFortranMatcher = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeMatcher   = regexp.MustCompile(`'shape'\s*:\s*\(\s*(\d+)\s*,\s*(\d+)\s*,?\s*\)`)
+)
+
+// Read a NumPy .npy file holding a square adjacency matrix of booleans, integers
+// or floats. Any nonzero entry is an edge weighted by the entry.
+func ReadNPY(filename string) *network.AdjacencyList {
+	return parseNPY(readFileOrPanic(filename)).build()
+}
+
+func parseNPY(contents []byte) *rawNetwork {
+	if len(contents) < 10 || string(contents[:6]) != string(npyMagic) {
+		panic(fmt.Errorf("parsing error: not an NPY file"))
+	}
+	majorVersion := contents[6]
+	var headerLen, dataStart int
+	if majorVersion == 1 {
+		headerLen = int(binary.LittleEndian.Uint16(contents[8:10]))
+		dataStart = 10 + headerLen
+	} else {
+		if len(contents) < 12 {
+			panic(fmt.Errorf("parsing error: NPY file is too short to have a header"))
+		}
+		headerLen = int(binary.LittleEndian.Uint32(contents[8:12]))
+		dataStart = 12 + headerLen
+	}
+	if dataStart > len(contents) || dataStart < headerLen {
+		panic(fmt.Errorf("parsing error: NPY header is %d bytes long, but the file is only %d bytes", headerLen, len(contents)))
+	}
+	header := string(contents[dataStart-headerLen : dataStart])
+
+	descr := npyDescrMatcher.FindStringSubmatch(header)
+	shape := npyShapeMatcher.FindStringSubmatch(header)
+	if descr == nil || shape == nil {
+		panic(fmt.Errorf("parsing error: unsupported NPY header %s", strings.TrimSpace(header)))
+	}
+	rows := atoiOrPanic(shape[1])
+	cols := atoiOrPanic(shape[2])
+	if rows != cols {
+		panic(fmt.Errorf("parsing error: adjacency matrix must be square, got %dx%d", rows, cols))
+	}
+	fortranOrder := npyFortranMatcher.FindStringSubmatch(header)
+	isFortranOrder := fortranOrder != nil && fortranOrder[1] == "True"
+
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if descr[1] == ">" {
+		byteOrder = binary.BigEndian
+	}
+	kind := descr[2]
+	size := atoiOrPanic(descr[3])
+	N := rows
+	data := contents[dataStart:]
+	if len(data) < N*N*size {
+		panic(fmt.Errorf("parsing error: NPY file has %d bytes of data, expected %d", len(data), N*N*size))
+	}
+
+	at := func(i, j int) float64 {
+		index := i*N + j
+		if isFortranOrder {
+			index = j*N + i
+		}
+		return npyValue(data[index*size:(index+1)*size], kind, byteOrder)
+	}
+	return rawFromMatrix(N, at)
+}
+
+func npyValue(b []byte, kind string, byteOrder binary.ByteOrder) float64 {
+	switch {
+	case kind == "f" && len(b) == 8:
+		return math.Float64frombits(byteOrder.Uint64(b))
+	case kind == "f" && len(b) == 4:
+		return float64(math.Float32frombits(byteOrder.Uint32(b)))
+	case kind == "i" && len(b) == 1:
+		return float64(int8(b[0]))
+	case kind == "i" && len(b) == 2:
+		return float64(int16(byteOrder.Uint16(b)))
+	case kind == "i" && len(b) == 4:
+		return float64(int32(byteOrder.Uint32(b)))
+	case kind == "i" && len(b) == 8:
+		return float64(int64(byteOrder.Uint64(b)))
+	case (kind == "u" || kind == "b") && len(b) == 1:
+		return float64(b[0])
+	case kind == "u" && len(b) == 2:
+		return float64(byteOrder.Uint16(b))
+	case kind == "u" && len(b) == 4:
+		return float64(byteOrder.Uint32(b))
+	case kind == "u" && len(b) == 8:
+		return float64(byteOrder.Uint64(b))
+	}
+	panic(fmt.Errorf("parsing error: unsupported NPY element type %s%d", kind, len(b)))
+}
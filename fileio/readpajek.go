@@ -0,0 +1,85 @@
+package fileio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Read a Pajek (.net) file. Vertex labels are kept as label attributes and arcs
+// are treated the same as edges since networks are undirected.
+func ReadPajek(filename string) *network.AdjacencyList {
+	return parsePajek(readFileOrPanic(filename)).build()
+}
+
+func parsePajek(contents []byte) *rawNetwork {
+	nodes := make([]graph.Node, 0)
+	attributes := make(map[int64][]network.Attribute)
+	edges := make([]graph.Edge, 0)
+	section := ""
+	for lineNum, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '%' {
+			continue
+		}
+		fields := splitQuoted(line)
+		if line[0] == '*' {
+			section = strings.ToLower(fields[0])
+			if section == "*vertices" {
+				if len(fields) < 2 {
+					panic(fmt.Errorf("parsing error: *Vertices is missing the number of vertices at line: %d", lineNum+1))
+				}
+				N := atoiOrPanic(fields[1])
+				for i := 0; i < N; i++ {
+					nodes = append(nodes, network.NewVertex(int64(i)))
+				}
+			}
+			continue
+		}
+
+		switch section {
+		case "*vertices":
+			// Pajek numbers vertices from 1
+			id := int64(atoiOrPanic(fields[0]) - 1)
+			if len(fields) > 1 {
+				label := strings.Trim(fields[1], `"`)
+				attributes[id] = []network.Attribute{{Key: "label", Value: label, Quoted: true}}
+			}
+		case "*edges", "*arcs":
+			u := network.NewVertex(int64(atoiOrPanic(fields[0]) - 1))
+			v := network.NewVertex(int64(atoiOrPanic(fields[1]) - 1))
+			edges = append(edges, network.NewLink(u, v))
+		case "*edgeslist", "*arcslist":
+			u := network.NewVertex(int64(atoiOrPanic(fields[0]) - 1))
+			for _, field := range fields[1:] {
+				v := network.NewVertex(int64(atoiOrPanic(field) - 1))
+				edges = append(edges, network.NewLink(u, v))
+			}
+		}
+	}
+	return newRawNetworkFrom(nodes, edges, attributes)
+}
+
+// Split a line on whitespace while keeping quoted strings together.
+func splitQuoted(line string) []string {
+	fields := make([]string, 0)
+	var sBuilder strings.Builder
+	inQuotes := false
+	for _, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if inQuotes || (c != ' ' && c != '\t' && c != '\r') {
+			sBuilder.WriteRune(c)
+		} else if sBuilder.Len() > 0 {
+			fields = append(fields, sBuilder.String())
+			sBuilder.Reset()
+		}
+	}
+	if sBuilder.Len() > 0 {
+		fields = append(fields, sBuilder.String())
+	}
+	return fields
+}
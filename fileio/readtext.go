@@ -0,0 +1,88 @@
+package fileio
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Read a file where each line holds the two endpoints of an edge. Any columns
+// after the endpoints (such as weights) are ignored. A line with a single name
+// declares an isolated node. Lines starting with # or % are comments.
+func ReadEdgeList(filename string) *network.AdjacencyList {
+	return parsePlainEdgeList(readFileOrPanic(filename)).build()
+}
+
+func parsePlainEdgeList(contents []byte) *rawNetwork {
+	namer := newNodeNamer()
+	pairs := make([][2]string, 0)
+	for _, line := range dataLines(string(contents)) {
+		fields := splitRow(line)
+		namer.add(fields[0])
+		if len(fields) > 1 {
+			namer.add(fields[1])
+			pairs = append(pairs, [2]string{fields[0], fields[1]})
+		}
+	}
+
+	edges := make([]graph.Edge, len(pairs))
+	for i, pair := range pairs {
+		u := network.NewVertex(namer.id(pair[0]))
+		v := network.NewVertex(namer.id(pair[1]))
+		edges[i] = network.NewLink(u, v)
+	}
+	return newRawNetworkFrom(namer.nodes(), edges, namer.attributes())
+}
+
+// Read a file containing a square adjacency matrix with one row per line. Entries
+// may be separated by whitespace or commas, and any nonzero entry is an edge
+// weighted by the entry.
+func ReadAdjacencyMatrix(filename string) *network.AdjacencyList {
+	return parseAdjacencyMatrix(readFileOrPanic(filename)).build()
+}
+
+func parseAdjacencyMatrix(contents []byte) *rawNetwork {
+	rows := dataLines(string(contents))
+	N := len(rows)
+	entries := make([]float64, N*N)
+	for i, row := range rows {
+		fields := splitRow(row)
+		if len(fields) != N {
+			panic(fmt.Errorf("parsing error: row %d of the adjacency matrix has %d entries, expected %d",
+				i, len(fields), N))
+		}
+		for j, field := range fields {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				panic(err)
+			}
+			entries[i*N+j] = value
+		}
+	}
+	return rawFromMatrix(N, func(i, j int) float64 { return entries[i*N+j] })
+}
+
+// Build a network with nodes 0 to N-1 and an edge between i and j if either
+// at(i, j) or at(j, i) is nonzero. The edge is weighted by the first of them that
+// is nonzero.
+func rawFromMatrix(N int, at func(i, j int) float64) *rawNetwork {
+	nodes := make([]graph.Node, N)
+	for i := range nodes {
+		nodes[i] = network.NewVertex(int64(i))
+	}
+	edges := make([]graph.Edge, 0)
+	for i := 0; i < N; i++ {
+		for j := i; j < N; j++ {
+			weight := at(i, j)
+			if weight == 0 {
+				weight = at(j, i)
+			}
+			if weight != 0 {
+				edges = append(edges, network.NewWeightedLink(nodes[i], nodes[j], weight))
+			}
+		}
+	}
+	return newRawNetworkFrom(nodes, edges, make(map[int64][]network.Attribute))
+}
@@ -0,0 +1,408 @@
+package fileio
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// The kinds of problems that make a network file unsafe to use as is
+type ProblemKind int
+
+const (
+	SelfLoop ProblemKind = iota
+	DuplicateEdge
+	DuplicateNode
+	// an edge has an endpoint that has no node declaration
+	UndeclaredNode
+	// a node's ID isn't in 0 to N-1, so it can't be used as a matrix index
+	IDOutOfRange
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case SelfLoop:
+		return "self loop"
+	case DuplicateEdge:
+		return "duplicate edge"
+	case DuplicateNode:
+		return "duplicate node"
+	case UndeclaredNode:
+		return "undeclared node"
+	case IDOutOfRange:
+		return "ID out of range"
+	}
+	return "unknown problem"
+}
+
+// A problem found in a network file
+type Problem struct {
+	Kind ProblemKind
+	// where the offending node or edge starts in the file. Both are 0 for formats
+	// that don't keep track of where things are and for networks that have
+	// already been read.
+	Line   int
+	Column int
+	// the offending node for node problems and undeclared nodes
+	Node int64
+	// the endpoints of the offending edge for edge problems and undeclared nodes
+	Source int64
+	Target int64
+	// true if the problem was fixed while reading the file
+	Repaired bool
+}
+
+func (p Problem) String() string {
+	var subject string
+	switch p.Kind {
+	case SelfLoop, DuplicateEdge:
+		subject = fmt.Sprintf("edge %d-%d", p.Source, p.Target)
+	case UndeclaredNode:
+		subject = fmt.Sprintf("node %d in edge %d-%d", p.Node, p.Source, p.Target)
+	default:
+		subject = fmt.Sprintf("node %d", p.Node)
+	}
+	str := fmt.Sprintf("line: %d col: %d: %s: %s", p.Line, p.Column, p.Kind, subject)
+	if p.Repaired {
+		str += " (repaired)"
+	}
+	return str
+}
+
+// The problems to fix while reading a network file
+type RepairOptions struct {
+	DropSelfLoops bool
+	// remove repeated edges and node declarations, keeping the first
+	Deduplicate bool
+	// declare the nodes that edges refer to but that were never declared
+	AddUndeclaredNodes bool
+	// renumber the nodes to 0 to N-1 in order of their original IDs. A node
+	// that doesn't have a label is given its original ID as its label.
+	RemapIDs bool
+}
+
+// Every repair
+var RepairAll = RepairOptions{
+	DropSelfLoops:      true,
+	Deduplicate:        true,
+	AddUndeclaredNodes: true,
+	RemapIDs:           true,
+}
+
+// Return every problem in a network file without building the network. The
+// file can be in any format ReadNetwork reads.
+func ValidateFile(filename string) []Problem {
+	contents := readFileOrPanic(filename)
+	return parseNetworkRaw(detectFormat(filename, contents), contents).validate(RepairOptions{})
+}
+
+// Read a network file like ReadNetwork while fixing the problems selected by
+// options. Every problem found is returned, including the ones that weren't
+// repaired.
+func ReadFileRepaired(filename string, options RepairOptions) (*network.AdjacencyList, []Problem) {
+	contents := readFileOrPanic(filename)
+	return repairRaw(parseNetworkRaw(detectFormat(filename, contents), contents), options)
+}
+
+// Return every problem in a network that has already been read, such as one
+// from ReadFileCached or a class.
+func ValidateNetwork(net *network.AdjacencyList) []Problem {
+	return rawFromNetwork(net).validate(RepairOptions{})
+}
+
+// Return a copy of the network with the problems selected by options fixed
+// along with every problem found.
+func RepairNetwork(net *network.AdjacencyList, options RepairOptions) (*network.AdjacencyList, []Problem) {
+	return repairRaw(rawFromNetwork(net), options)
+}
+
+func repairRaw(raw *rawNetwork, options RepairOptions) (*network.AdjacencyList, []Problem) {
+	problems := raw.validate(options)
+	raw.repair(options)
+	// only the options decide whether undeclared nodes are added
+	raw.declareEndpoints = false
+	return raw.build(), problems
+}
+
+type location struct {
+	line   int
+	column int
+}
+
+// The nodes, attributes and edges of a network exactly as they were read from a
+// file, before anything has been checked
+type rawNetwork struct {
+	nodes         []graph.Node
+	nodeAttrs     [][]network.Attribute
+	nodeLocations []location
+	edges         []graph.Edge
+	edgeLocations []location
+	// set by formats whose readers accept edges to nodes that weren't declared.
+	// build adds those nodes unless they are left to the repair options.
+	declareEndpoints bool
+	// the attributes of nodes that are only named by edges, such as their labels
+	endpointAttrs map[int64][]network.Attribute
+}
+
+func newRawNetwork() *rawNetwork {
+	return &rawNetwork{
+		nodes:         make([]graph.Node, 0),
+		nodeAttrs:     make([][]network.Attribute, 0),
+		nodeLocations: make([]location, 0),
+		edges:         make([]graph.Edge, 0),
+		edgeLocations: make([]location, 0),
+	}
+}
+
+// Make a rawNetwork from the parts of a network read from a file that doesn't
+// keep track of where the nodes and edges are.
+func newRawNetworkFrom(nodes []graph.Node, edges []graph.Edge, attributes map[int64][]network.Attribute) *rawNetwork {
+	raw := newRawNetwork()
+	for _, u := range nodes {
+		raw.addNode(u, attributes[u.ID()], location{})
+	}
+	for _, e := range edges {
+		raw.addEdge(e, location{})
+	}
+	return raw
+}
+
+func rawFromNetwork(net *network.AdjacencyList) *rawNetwork {
+	raw := newRawNetwork()
+	for _, u := range graph.NodesOf(net.Nodes()) {
+		attrs := append([]network.Attribute{}, net.Attributes(u.ID())...)
+		raw.addNode(u, attrs, location{})
+	}
+	for _, e := range graph.EdgesOf(net.Edges()) {
+		u, v := e.From(), e.To()
+		raw.addEdge(network.NewWeightedLink(u, v, net.EdgeWeight(u.ID(), v.ID())), location{})
+	}
+	return raw
+}
+
+func (raw *rawNetwork) addNode(u graph.Node, attrs []network.Attribute, loc location) {
+	raw.nodes = append(raw.nodes, u)
+	raw.nodeAttrs = append(raw.nodeAttrs, attrs)
+	raw.nodeLocations = append(raw.nodeLocations, loc)
+}
+
+func (raw *rawNetwork) addEdge(e graph.Edge, loc location) {
+	raw.edges = append(raw.edges, e)
+	raw.edgeLocations = append(raw.edgeLocations, loc)
+}
+
+// Build the network without fixing anything other than adding undeclared nodes
+// for formats that allow them. If a node was declared twice, the attributes of
+// the last declaration are used.
+func (raw *rawNetwork) build() *network.AdjacencyList {
+	if raw.declareEndpoints {
+		raw.addUndeclaredNodes()
+	}
+	attributes := make(map[int64][]network.Attribute)
+	for i, u := range raw.nodes {
+		attributes[u.ID()] = raw.nodeAttrs[i]
+	}
+	return newNetwork(raw.nodes, raw.edges, attributes)
+}
+
+// Return every problem in the network. Problems that options would fix are
+// marked as repaired.
+func (raw *rawNetwork) validate(options RepairOptions) []Problem {
+	problems := make([]Problem, 0)
+	declared := make(map[int64]bool)
+	for i, u := range raw.nodes {
+		if declared[u.ID()] {
+			problems = append(problems, nodeProblem(DuplicateNode, u.ID(), raw.nodeLocations[i], options.Deduplicate))
+		}
+		declared[u.ID()] = true
+	}
+
+	// undeclared nodes change N, so they have to be counted before checking IDs
+	undeclared := make(map[int64]bool)
+	undeclaredOrder := make([]int64, 0)
+	undeclaredLocations := make(map[int64]location)
+	seenEdges := make(map[[2]int64]bool)
+	for i, e := range raw.edges {
+		loc := raw.edgeLocations[i]
+		u, v := e.From().ID(), e.To().ID()
+		endpoints := []int64{u, v}
+		if u == v {
+			endpoints = endpoints[:1]
+		}
+		for _, id := range endpoints {
+			if !declared[id] {
+				problem := edgeProblem(UndeclaredNode, u, v, loc, options.AddUndeclaredNodes)
+				problem.Node = id
+				problems = append(problems, problem)
+				if !undeclared[id] {
+					undeclaredOrder = append(undeclaredOrder, id)
+					undeclaredLocations[id] = loc
+				}
+				undeclared[id] = true
+			}
+		}
+		if u == v {
+			problems = append(problems, edgeProblem(SelfLoop, u, v, loc, options.DropSelfLoops))
+			continue
+		}
+		key := [2]int64{u, v}
+		if u > v {
+			key = [2]int64{v, u}
+		}
+		if seenEdges[key] {
+			problems = append(problems, edgeProblem(DuplicateEdge, u, v, loc, options.Deduplicate))
+		}
+		seenEdges[key] = true
+	}
+
+	N := int64(len(declared))
+	if options.AddUndeclaredNodes {
+		N += int64(len(undeclared))
+	}
+	reported := make(map[int64]bool)
+	for i, u := range raw.nodes {
+		if (u.ID() < 0 || u.ID() >= N) && !reported[u.ID()] {
+			problems = append(problems, nodeProblem(IDOutOfRange, u.ID(), raw.nodeLocations[i], options.RemapIDs))
+			reported[u.ID()] = true
+		}
+	}
+	// undeclared nodes end up in the network whether or not they are declared by
+	// the repair, so their IDs have to fit too
+	for _, id := range undeclaredOrder {
+		if id < 0 || id >= N {
+			problems = append(problems, nodeProblem(IDOutOfRange, id, undeclaredLocations[id], options.RemapIDs))
+		}
+	}
+	return problems
+}
+
+func nodeProblem(kind ProblemKind, id int64, loc location, repaired bool) Problem {
+	return Problem{Kind: kind, Line: loc.line, Column: loc.column, Node: id, Repaired: repaired}
+}
+
+func edgeProblem(kind ProblemKind, u, v int64, loc location, repaired bool) Problem {
+	return Problem{Kind: kind, Line: loc.line, Column: loc.column, Source: u, Target: v, Repaired: repaired}
+}
+
+// Fix the problems selected by options in place.
+func (raw *rawNetwork) repair(options RepairOptions) {
+	if options.Deduplicate {
+		raw.removeDuplicateNodes()
+	}
+	if options.AddUndeclaredNodes {
+		raw.addUndeclaredNodes()
+	}
+	if options.DropSelfLoops || options.Deduplicate {
+		raw.filterEdges(options.DropSelfLoops, options.Deduplicate)
+	}
+	if options.RemapIDs {
+		raw.remapIDs()
+	}
+}
+
+func (raw *rawNetwork) removeDuplicateNodes() {
+	declared := make(map[int64]bool)
+	deduped := newRawNetwork()
+	for i, u := range raw.nodes {
+		if !declared[u.ID()] {
+			deduped.addNode(u, raw.nodeAttrs[i], raw.nodeLocations[i])
+			declared[u.ID()] = true
+		}
+	}
+	raw.nodes, raw.nodeAttrs, raw.nodeLocations = deduped.nodes, deduped.nodeAttrs, deduped.nodeLocations
+}
+
+// Undeclared nodes are added at the location of the first edge that refers to
+// them. They don't have attributes unless the reader kept some in endpointAttrs.
+func (raw *rawNetwork) addUndeclaredNodes() {
+	declared := make(map[int64]bool)
+	for _, u := range raw.nodes {
+		declared[u.ID()] = true
+	}
+	for i, e := range raw.edges {
+		for _, id := range []int64{e.From().ID(), e.To().ID()} {
+			if !declared[id] {
+				attrs := append([]network.Attribute{}, raw.endpointAttrs[id]...)
+				raw.addNode(network.NewVertex(id), attrs, raw.edgeLocations[i])
+				declared[id] = true
+			}
+		}
+	}
+}
+
+func (raw *rawNetwork) filterEdges(dropSelfLoops, deduplicate bool) {
+	seenEdges := make(map[[2]int64]bool)
+	edges := make([]graph.Edge, 0, len(raw.edges))
+	locations := make([]location, 0, len(raw.edges))
+	for i, e := range raw.edges {
+		u, v := e.From().ID(), e.To().ID()
+		if dropSelfLoops && u == v {
+			continue
+		}
+		key := [2]int64{u, v}
+		if u > v {
+			key = [2]int64{v, u}
+		}
+		if deduplicate && seenEdges[key] {
+			continue
+		}
+		seenEdges[key] = true
+		edges = append(edges, e)
+		locations = append(locations, raw.edgeLocations[i])
+	}
+	raw.edges, raw.edgeLocations = edges, locations
+}
+
+// Renumber the nodes to 0 to N-1 keeping the order of their IDs. Undeclared
+// nodes that are still referred to by edges are numbered after the declared nodes.
+func (raw *rawNetwork) remapIDs() {
+	oldIDs := make([]int64, 0, len(raw.nodes))
+	newIDs := make(map[int64]int64)
+	for _, u := range raw.nodes {
+		if _, ok := newIDs[u.ID()]; !ok {
+			newIDs[u.ID()] = 0
+			oldIDs = append(oldIDs, u.ID())
+		}
+	}
+	sort.Slice(oldIDs, func(i, j int) bool { return oldIDs[i] < oldIDs[j] })
+	for _, e := range raw.edges {
+		for _, id := range []int64{e.From().ID(), e.To().ID()} {
+			if _, ok := newIDs[id]; !ok {
+				newIDs[id] = 0
+				oldIDs = append(oldIDs, id)
+			}
+		}
+	}
+	for i, id := range oldIDs {
+		newIDs[id] = int64(i)
+	}
+
+	for i, u := range raw.nodes {
+		raw.nodes[i] = network.NewVertex(newIDs[u.ID()])
+		if !hasKey(raw.nodeAttrs[i], "label") {
+			label := network.Attribute{Key: "label", Value: strconv.FormatInt(u.ID(), 10), Quoted: true}
+			raw.nodeAttrs[i] = append([]network.Attribute{label}, raw.nodeAttrs[i]...)
+		}
+	}
+	for i, e := range raw.edges {
+		u := network.NewVertex(newIDs[e.From().ID()])
+		v := network.NewVertex(newIDs[e.To().ID()])
+		if weighted, ok := e.(graph.WeightedEdge); ok {
+			raw.edges[i] = network.NewWeightedLink(u, v, weighted.Weight())
+		} else {
+			raw.edges[i] = network.NewLink(u, v)
+		}
+	}
+}
+
+func hasKey(attrs []network.Attribute, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
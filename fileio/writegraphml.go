@@ -0,0 +1,97 @@
+package fileio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Write the network to a GraphML file. Each attribute key becomes a GraphML key
+// whose type is string if any value was quoted, otherwise int or double. Edge
+// weights are written to a weight key if any of them aren't 1.
+func WriteGraphML(filename string, net *network.AdjacencyList) {
+	writeFileOrPanic(filename, func(w io.Writer) { writeGraphML(w, net) })
+}
+
+func writeGraphML(w io.Writer, net *network.AdjacencyList) {
+	ids := sortedNodeIDs(net)
+
+	// declare a key for every attribute in the order they first appear
+	keys := make([]graphMLKey, 0)
+	keyIndex := make(map[string]int)
+	for _, id := range ids {
+		for _, attr := range net.Attributes(id) {
+			i, ok := keyIndex[attr.Key]
+			if !ok {
+				i = len(keys)
+				keyIndex[attr.Key] = i
+				keys = append(keys, graphMLKey{
+					ID:   fmt.Sprintf("d%d", i),
+					For:  "node",
+					Name: attr.Key,
+					Type: "int",
+				})
+			}
+			keys[i].Type = widenGraphMLType(keys[i].Type, attr)
+		}
+	}
+
+	edges := graph.EdgesOf(net.Edges())
+	weightKey := ""
+	for _, e := range edges {
+		if net.EdgeWeight(e.From().ID(), e.To().ID()) != 1 {
+			weightKey = fmt.Sprintf("d%d", len(keys))
+			keys = append(keys, graphMLKey{ID: weightKey, For: "edge", Name: "weight", Type: "double"})
+			break
+		}
+	}
+
+	file := graphMLFile{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  keys,
+		Graph: graphMLGraph{EdgeDefault: "undirected"},
+	}
+	for _, id := range ids {
+		node := graphMLNode{ID: strconv.FormatInt(id, 10)}
+		for _, attr := range net.Attributes(id) {
+			node.Data = append(node.Data, graphMLData{keys[keyIndex[attr.Key]].ID, attr.Value})
+		}
+		file.Graph.Nodes = append(file.Graph.Nodes, node)
+	}
+	for _, e := range edges {
+		edge := graphMLEdge{
+			Source: strconv.FormatInt(e.From().ID(), 10),
+			Target: strconv.FormatInt(e.To().ID(), 10),
+		}
+		if weight := net.EdgeWeight(e.From().ID(), e.To().ID()); weight != 1 {
+			edge.Data = []graphMLData{{weightKey, strconv.FormatFloat(weight, 'g', -1, 64)}}
+		}
+		file.Graph.Edges = append(file.Graph.Edges, edge)
+	}
+
+	io.WriteString(w, xml.Header)
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		panic(err)
+	}
+	io.WriteString(w, "\n")
+}
+
+// Return the narrowest GraphML type that holds both values of type current and attr.
+func widenGraphMLType(current string, attr network.Attribute) string {
+	if current == "string" || attr.Quoted {
+		return "string"
+	}
+	if _, err := strconv.ParseInt(attr.Value, 10, 64); err == nil {
+		return current
+	}
+	if _, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+		return "double"
+	}
+	return "string"
+}
@@ -0,0 +1,108 @@
+package fileio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Write the network to a file in GML. Node attributes are written in the order
+// they were added so that ReadFile gives back the same network. Edge weights are
+// only written if they aren't 1.
+func WriteGML(filename string, net *network.AdjacencyList) {
+	writeFileOrPanic(filename, func(w io.Writer) { writeGML(w, net) })
+}
+
+func writeGML(w io.Writer, net *network.AdjacencyList) {
+	fmt.Fprintln(w, "graph [")
+	for _, id := range sortedNodeIDs(net) {
+		fmt.Fprintln(w, "  node [")
+		fmt.Fprintf(w, "    id %d\n", id)
+		for _, attr := range net.Attributes(id) {
+			fmt.Fprintf(w, "    %s %s\n", attr.Key, formatAttributeValue(attr))
+		}
+		fmt.Fprintln(w, "  ]")
+	}
+	for _, e := range graph.EdgesOf(net.Edges()) {
+		fmt.Fprintln(w, "  edge [")
+		fmt.Fprintf(w, "    source %d\n", e.From().ID())
+		fmt.Fprintf(w, "    target %d\n", e.To().ID())
+		if weight := net.EdgeWeight(e.From().ID(), e.To().ID()); weight != 1 {
+			fmt.Fprintf(w, "    weight %s\n", strconv.FormatFloat(weight, 'g', -1, 64))
+		}
+		fmt.Fprintln(w, "  ]")
+	}
+	fmt.Fprintln(w, "]")
+}
+
+// GML strings can't contain quotes, so they are written as HTML entities the
+// same way NetworkX does.
+var (
+	gmlEscaper   = strings.NewReplacer("&", "&amp;", `"`, "&quot;")
+	gmlUnescaper = strings.NewReplacer("&quot;", `"`, "&amp;", "&")
+)
+
+func formatAttributeValue(attr network.Attribute) string {
+	if attr.Quoted {
+		return `"` + gmlEscaper.Replace(attr.Value) + `"`
+	}
+	return attr.Value
+}
+
+// Write the network to a file with one edge per line. Isolated nodes are written
+// on a line by themselves so that they aren't lost. Attributes are not written.
+func WriteEdgeList(filename string, net *network.AdjacencyList) {
+	writeFileOrPanic(filename, func(w io.Writer) { writeEdgeList(w, net) })
+}
+
+func writeEdgeList(w io.Writer, net *network.AdjacencyList) {
+	for _, id := range sortedNodeIDs(net) {
+		if net.From(id).Len() == 0 {
+			fmt.Fprintf(w, "%d\n", id)
+		}
+	}
+	for _, e := range graph.EdgesOf(net.Edges()) {
+		fmt.Fprintf(w, "%d %d\n", e.From().ID(), e.To().ID())
+	}
+}
+
+func sortedNodeIDs(net *network.AdjacencyList) []int64 {
+	nodes := net.Nodes()
+	ids := make([]int64, 0, nodes.Len())
+	for nodes.Next() {
+		ids = append(ids, nodes.Node().ID())
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Create filename and hand a buffered writer for it to write.
+func writeFileOrPanic(filename string, write func(w io.Writer)) {
+	outFile, err := os.Create(filename)
+	if err != nil {
+		panic(err)
+	}
+	closed := false
+	defer func() {
+		if !closed {
+			outFile.Close()
+		}
+	}()
+	writer := bufio.NewWriter(outFile)
+	write(writer)
+	if err := writer.Flush(); err != nil {
+		panic(err)
+	}
+	// the last of the data may only be written when the file is closed
+	closed = true
+	if err := outFile.Close(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,54 @@
+package fileio
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+// Write nets to a .tar.gz file that ReadClass can read. Each network is written
+// in GML to <className>/instance-<i>.txt where className is the name of the
+// tarball without its extension.
+func WriteClass(pathToClass string, nets []*network.AdjacencyList) {
+	className := strings.TrimSuffix(filepath.Base(pathToClass), ".tar.gz")
+	writeFileOrPanic(pathToClass, func(w io.Writer) { writeClass(w, className, nets) })
+}
+
+func writeClass(w io.Writer, className string, nets []*network.AdjacencyList) {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	modTime := time.Now()
+	for i, net := range nets {
+		var buffer bytes.Buffer
+		writeGML(&buffer, net)
+		header := &tar.Header{
+			Name:    filepath.Join(className, fmt.Sprintf("instance-%d.txt", i)),
+			Mode:    0644,
+			Size:    int64(buffer.Len()),
+			ModTime: modTime,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			panic(err)
+		}
+		if _, err := tarWriter.Write(buffer.Bytes()); err != nil {
+			panic(err)
+		}
+	}
+
+	// closing the writers flushes them, so the file is closed by writeFileOrPanic
+	// after both of them
+	if err := tarWriter.Close(); err != nil {
+		panic(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		panic(err)
+	}
+}
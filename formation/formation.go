@@ -0,0 +1,235 @@
+package formation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// The parameters of the agent based formation model. Agents are placed at
+// random in the unit square and split at random into NumGroups groups. Every
+// round, each agent in a random order drops ties if it has too many and tries to
+// form a tie if it has too few. How much an agent wants a tie to another agent is
+//
+//	Baseline + Homophily*sameGroup + Proximity*exp(-distance/ProximityScale) + TriadicClosure*mutualNeighbors
+//
+// where sameGroup is 1 if they are in the same group and 0 otherwise.
+type Config struct {
+	N      int
+	Rounds int
+	// an agent's group is stored as its community attribute
+	NumGroups int
+	// the degree each agent aims for is drawn uniformly from MinDegree to MaxDegree
+	MinDegree int
+	MaxDegree int
+
+	Baseline       float64
+	Homophily      float64
+	Proximity      float64
+	ProximityScale float64
+	TriadicClosure float64
+	// the chance that an agent with as many ties as it wants drops its least
+	// wanted tie to look for a better one
+	Churn float64
+}
+
+// Return a class name for networks made with the config that ParseClassName can
+// read back.
+func (c Config) ClassName() string {
+	return fmt.Sprintf("AgentFormation(N=%d,rounds=%d,groups=%d,degree=(%d, %d),baseline=%v,homophily=%v,proximity=%v,scale=%v,closure=%v,churn=%v)",
+		c.N, c.Rounds, c.NumGroups, c.MinDegree, c.MaxDegree, c.Baseline, c.Homophily,
+		c.Proximity, c.ProximityScale, c.TriadicClosure, c.Churn)
+}
+
+func (c Config) check() error {
+	if c.N <= 0 {
+		return fmt.Errorf("the number of agents (%d) has to be positive", c.N)
+	}
+	if c.Rounds < 0 {
+		return fmt.Errorf("the number of rounds (%d) can't be negative", c.Rounds)
+	}
+	if c.NumGroups <= 0 {
+		return fmt.Errorf("the number of groups (%d) has to be positive", c.NumGroups)
+	}
+	if c.MinDegree < 0 || c.MaxDegree < c.MinDegree {
+		return fmt.Errorf("the degree range (%d, %d) has to go from a nonnegative minimum up to the maximum",
+			c.MinDegree, c.MaxDegree)
+	}
+	if c.Proximity != 0 && c.ProximityScale <= 0 {
+		return fmt.Errorf("the proximity scale (%v) has to be positive", c.ProximityScale)
+	}
+	if c.Churn < 0 || c.Churn > 1 {
+		return fmt.Errorf("the churn (%v) isn't between 0 and 1", c.Churn)
+	}
+	return nil
+}
+
+type agent struct {
+	group        int
+	x, y         float64
+	targetDegree int
+}
+
+type model struct {
+	config Config
+	agents []agent
+	net    *network.AdjacencyList
+	rng    *rand.Rand
+}
+
+// Run the formation model. The network has a label, two layout entries holding
+// the agent's position and a community attribute holding its group for every
+// agent. The same rng seed always gives the same network. It panics if the
+// config doesn't make sense, such as when MaxDegree is below MinDegree.
+func Generate(config Config, rng *rand.Rand) *network.AdjacencyList {
+	if err := config.check(); err != nil {
+		panic(err)
+	}
+	m := newModel(config, rng)
+	for round := 0; round < config.Rounds; round++ {
+		for _, u := range rng.Perm(config.N) {
+			m.takeTurn(int64(u))
+		}
+	}
+	return m.net
+}
+
+// Generate numInstances networks where instance i is made with a *rand.Rand
+// seeded with seed+i, so any instance can be made again on its own. The result
+// can be written with fileio.WriteClass.
+func GenerateClass(config Config, numInstances int, seed int64) []*network.AdjacencyList {
+	if err := config.check(); err != nil {
+		panic(err)
+	}
+	nets := make([]*network.AdjacencyList, numInstances)
+	for i := range nets {
+		nets[i] = Generate(config, rand.New(rand.NewSource(seed+int64(i))))
+	}
+	return nets
+}
+
+func newModel(config Config, rng *rand.Rand) *model {
+	nodes := make([]graph.Node, config.N)
+	adjList := make(map[int64][]graph.Node, config.N)
+	agents := make([]agent, config.N)
+	for i := range agents {
+		nodes[i] = network.NewVertex(int64(i))
+		adjList[int64(i)] = make([]graph.Node, 0)
+		agents[i] = agent{
+			group:        rng.Intn(config.NumGroups),
+			x:            rng.Float64(),
+			y:            rng.Float64(),
+			targetDegree: config.MinDegree + rng.Intn(config.MaxDegree-config.MinDegree+1),
+		}
+	}
+
+	net := network.NewAdjacencyList(nodes, adjList)
+	for i, a := range agents {
+		id := int64(i)
+		net.AddAttribute(id, network.Attribute{Key: "label", Value: strconv.Itoa(i), Quoted: true})
+		net.AddAttribute(id, network.Attribute{Key: "layout", Value: fmt.Sprint(a.x)})
+		net.AddAttribute(id, network.Attribute{Key: "layout", Value: fmt.Sprint(a.y)})
+		net.AddAttribute(id, network.Attribute{Key: network.CommunityKey, Value: strconv.Itoa(a.group)})
+	}
+	return &model{config: config, agents: agents, net: net, rng: rng}
+}
+
+func (m *model) takeTurn(u int64) {
+	degree := m.net.From(u).Len()
+	target := m.agents[u].targetDegree
+	for ; degree > target; degree-- {
+		m.dropWorstTie(u)
+	}
+	if degree == target && degree > 0 && m.rng.Float64() < m.config.Churn {
+		m.dropWorstTie(u)
+		degree--
+	}
+	if degree < target {
+		if v, ok := m.pickCandidate(u); ok && m.accepts(v, u) {
+			m.net.AddEdge(u, v)
+		}
+	}
+}
+
+// Pick an agent that u isn't tied to with probability proportional to how much
+// u wants a tie to it.
+func (m *model) pickCandidate(u int64) (int64, bool) {
+	scores := make([]float64, m.config.N)
+	total := 0.0
+	for v := range scores {
+		if int64(v) != u && !m.net.HasEdgeBetween(u, int64(v)) {
+			scores[v] = m.score(u, int64(v))
+			total += scores[v]
+		}
+	}
+	if total <= 0 {
+		return 0, false
+	}
+	r := m.rng.Float64() * total
+	for v, score := range scores {
+		r -= score
+		if score > 0 && r < 0 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// v accepts a tie from u if it has room for another tie or if it wants u more
+// than its least wanted tie, which it drops to make room.
+func (m *model) accepts(v, u int64) bool {
+	if m.net.From(v).Len() < m.agents[v].targetDegree {
+		return true
+	}
+	worst, worstScore, ok := m.worstTie(v)
+	if !ok || m.score(v, u) <= worstScore {
+		return false
+	}
+	m.net.RemoveEdge(v, worst)
+	return true
+}
+
+func (m *model) dropWorstTie(u int64) {
+	if worst, _, ok := m.worstTie(u); ok {
+		m.net.RemoveEdge(u, worst)
+	}
+}
+
+// Return the neighbor u wants least. Ties go to the lowest ID.
+func (m *model) worstTie(u int64) (worst int64, worstScore float64, ok bool) {
+	for _, v := range graph.NodesOf(m.net.From(u)) {
+		score := m.score(u, v.ID())
+		if !ok || score < worstScore || (score == worstScore && v.ID() < worst) {
+			worst, worstScore, ok = v.ID(), score, true
+		}
+	}
+	return worst, worstScore, ok
+}
+
+// How much u wants a tie to v
+func (m *model) score(u, v int64) float64 {
+	a, b := m.agents[u], m.agents[v]
+	score := m.config.Baseline
+	if a.group == b.group {
+		score += m.config.Homophily
+	}
+	if m.config.Proximity != 0 && m.config.ProximityScale > 0 {
+		distance := math.Hypot(a.x-b.x, a.y-b.y)
+		score += m.config.Proximity * math.Exp(-distance/m.config.ProximityScale)
+	}
+	if m.config.TriadicClosure != 0 {
+		mutual := 0
+		for neighbors := m.net.From(u); neighbors.Next(); {
+			w := neighbors.Node().ID()
+			if w != v && m.net.HasEdgeBetween(w, v) {
+				mutual++
+			}
+		}
+		score += m.config.TriadicClosure * float64(mutual)
+	}
+	return score
+}
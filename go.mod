@@ -5,5 +5,5 @@ go 1.16
 require (
 	github.com/sbwhitecap/tqdm v0.0.0-20170314014342-7929e3102f57 // indirect
 	golang.org/x/exp v0.0.0-20210812203943-8c280c88aa00 // indirect
-	gonum.org/v1/gonum v0.9.3 // indirect
+	gonum.org/v1/gonum v0.9.3
 )
@@ -1,53 +1,127 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
+	"os"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	fio "github.com/GaudiestTooth17/irn-sim/fileio"
-	"github.com/GaudiestTooth17/irn-sim/network"
 	"github.com/GaudiestTooth17/irn-sim/sim"
 )
 
 func main() {
-	// if len(os.Args) < 2 {
-	// 	fmt.Printf("Usage: %s <network>\n", os.Args[0])
-	// 	return
-	// }
+	// pairs is how SimplePressure flickered before the mode could be chosen, so the
+	// default gives the same results as earlier runs
+	behaviorFlag := flag.String("behavior", "SimplePressure(radius=2, flicker_probability=0.25, flicker_mode=pairs)",
+		"the behavior agents use, written like its name such as StaticBehavior")
+	listBehaviors := flag.Bool("list-behaviors", false, "list the available behaviors and their parameters")
+	flag.Parse()
+	if *listBehaviors {
+		printBehaviors()
+		return
+	}
+	makeBehavior, err := sim.ParseBehavior(*behaviorFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	simsPerClassInstance := 1
 	seed := int64(69)
 	classPaths := getClassPaths()
 	csvLines := make([][]string, len(classPaths)*2)
+	metadatas := make([]fio.ClassMetadata, len(classPaths))
+	survivalRatesByClass := make([][]float64, len(classPaths))
+	costsByClass := make([][]sim.SocialCost, len(classPaths))
 	for i, classPath := range classPaths {
 		startTime := time.Now()
+		metadata, err := fio.ParseClassName(classPath)
+		if err != nil {
+			panic(err)
+		}
+		metadatas[i] = metadata
 		networkName := filepath.Base(classPath)
 		// strip off .tar.gz
 		networkName = networkName[:len(networkName)-7]
 		fmt.Printf("Running %d simulations on %s. ", simsPerClassInstance, networkName)
 
-		nets := fio.ReadClass(classPath)
+		nets := fio.IterateClassCached(classPath).Networks()
 		// set up the parameters
 		disease := sim.Disease{DaysInfectious: 4, TransProb: .2}
-		makeBehavior := func(net *network.AdjacencyList, rng *rand.Rand) sim.Behavior {
-			return sim.NewSimplePressureBehavior(net, rng, 2, .25)
-		}
 		makeSIR0 := func(N int, numToInfect int, rng *rand.Rand) sim.SIR {
 			return sim.MakeSir0(N, 1, rng)
 		}
 
 		// run a simulations
-		survivalRates := sim.SimOnManyNetworksForSurvivalRate(nets, makeSIR0, disease,
-			makeBehavior, 300, seed, simsPerClassInstance)
+		survivalRates, costs := sim.SimOnNetworkStreamForSurvivalAndCost(nets, makeSIR0, disease,
+			makeBehavior, 300, seed, simsPerClassInstance, runtime.NumCPU())
 		csvLines[2*i] = []string{networkName}
 		csvLines[2*i+1] = floatSliceToStrSlice(survivalRates)
+		survivalRatesByClass[i] = survivalRates
+		costsByClass[i] = costs
 
 		// report completion
 		fmt.Printf("Done (%v).\n", time.Since(startTime))
 	}
 	// save to csv
 	fio.WriteToCSV("results/survival rates (go).csv", csvLines)
+	fio.WriteToCSV("results/survival rates by parameter (go).csv",
+		makeParameterTable(metadatas, survivalRatesByClass, costsByClass))
+}
+
+func printBehaviors() {
+	for _, spec := range sim.Behaviors() {
+		fmt.Println(spec.Usage())
+		if spec.Doc != "" {
+			fmt.Printf("    %s\n", spec.Doc)
+		}
+		for _, param := range spec.Params {
+			fmt.Printf("    %s (%s", param.Name, param.Type)
+			if param.Default != "" {
+				fmt.Printf(", default %s", param.Default)
+			}
+			fmt.Printf("): %s\n", param.Doc)
+		}
+	}
+}
+
+// Make a table with one row per simulation and a column for the model and each
+// class parameter so that outcomes can be grouped by parameter. Classes that
+// don't have a parameter leave its column empty. Each row ends with the survival
+// rate and a summary of the social cost of the simulation.
+func makeParameterTable(metadatas []fio.ClassMetadata, survivalRatesByClass [][]float64, costsByClass [][]sim.SocialCost) [][]string {
+	paramColumns := make([]string, 0)
+	columnIndex := make(map[string]int)
+	for _, metadata := range metadatas {
+		names, _ := metadata.Columns()
+		for _, name := range names {
+			if _, ok := columnIndex[name]; !ok {
+				columnIndex[name] = len(paramColumns)
+				paramColumns = append(paramColumns, name)
+			}
+		}
+	}
+
+	header := append([]string{"model"}, paramColumns...)
+	table := [][]string{append(header, "survival_rate", "fraction_lost", "total_edge_days_lost", "peak_edges_removed")}
+	for i, metadata := range metadatas {
+		row := make([]string, len(paramColumns))
+		names, values := metadata.Columns()
+		for j, name := range names {
+			row[columnIndex[name]] = values[j]
+		}
+		for j, rate := range survivalRatesByClass[i] {
+			cost := costsByClass[i][j]
+			line := append([]string{metadata.Model}, row...)
+			table = append(table, append(line, fmt.Sprint(rate), fmt.Sprint(cost.FractionLost()),
+				fmt.Sprint(cost.TotalEdgeDaysLost()), fmt.Sprint(cost.PeakEdgesRemoved())))
+		}
+	}
+	return table
 }
 
 func floatSliceToStrSlice(fSlice []float64) []string {
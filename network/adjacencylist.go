@@ -1,89 +1,185 @@
 package network
 
 import (
+	"fmt"
 	"math"
+	"sort"
+	"sync"
 
 	"github.com/GaudiestTooth17/irn-sim/sets"
 	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
 	"gonum.org/v1/gonum/mat"
 )
 
 type AdjacencyList struct {
 	// nodes keyed by id
-	nodes []graph.Node
+	nodes map[int64]graph.Node
 	// node ID to the node's neighbors
 	adjList map[int64][]graph.Node
+	// guards m, dm and view, which are built lazily and may be requested by
+	// several goroutines at once
+	cacheLock sync.Mutex
 	// adjacency matrix
 	m *mat.Dense
 	// distance matrix
 	dm *mat.Dense
+	// read only copy of the network, built by View
+	view *View
+	// node ID to the node's attributes in the order they were read
+	attributes map[int64][]Attribute
+	// the weights of edges that don't have a weight of 1 keyed by their
+	// endpoints with the smaller ID first
+	weights map[[2]int64]float64
 }
 
 func NewAdjacencyList(nodes []graph.Node, adjList map[int64][]graph.Node) *AdjacencyList {
+	nodesByID := make(map[int64]graph.Node, len(nodes))
+	for _, u := range nodes {
+		nodesByID[u.ID()] = u
+	}
 	return &AdjacencyList{
-		nodes:   nodes,
-		adjList: adjList,
-		m:       nil,
-		dm:      nil,
+		nodes:      nodesByID,
+		adjList:    adjList,
+		m:          nil,
+		dm:         nil,
+		attributes: make(map[int64][]Attribute),
+		weights:    make(map[[2]int64]float64),
 	}
 }
 
-// part of the graph.Graph interface
+// part of the graph.Graph interface. Returns nil if there is no node with the ID.
 func (g *AdjacencyList) Node(id int64) graph.Node {
 	return g.nodes[id]
 }
 
-// part of the graph.Graph interface
+// part of the graph.Graph interface. Nodes are returned in order of their IDs,
+// which don't have to be 0 to N-1.
 func (g *AdjacencyList) Nodes() graph.Nodes {
-	allNodes := make([]graph.Node, len(g.nodes))
+	if len(g.nodes) == 0 {
+		return graph.Empty
+	}
+	allNodes := make([]graph.Node, 0, len(g.nodes))
 	for _, node := range g.nodes {
-		allNodes[node.ID()] = node
+		allNodes = append(allNodes, node)
 	}
-	return &SliceIterator{allNodes, 0}
+	sort.Slice(allNodes, func(i, j int) bool { return allNodes[i].ID() < allNodes[j].ID() })
+	return NewSliceIterator(allNodes)
 }
 
 // part of the graph.Graph interface
 func (g *AdjacencyList) From(id int64) graph.Nodes {
-	return &SliceIterator{g.adjList[id], 0}
+	if len(g.adjList[id]) == 0 {
+		return graph.Empty
+	}
+	return NewSliceIterator(g.adjList[id])
 }
 
 // part of the graph.Graph interface
 func (g *AdjacencyList) HasEdgeBetween(xid, yid int64) bool {
-	if g.m != nil {
-		return g.m.At(int(xid), int(yid)) == 1
+	g.cacheLock.Lock()
+	m := g.m
+	g.cacheLock.Unlock()
+	if m != nil {
+		N, _ := m.Dims()
+		if xid >= 0 && yid >= 0 && xid < int64(N) && yid < int64(N) {
+			return m.At(int(xid), int(yid)) == 1
+		}
 	}
-	for _, v := range g.adjList[xid] {
-		if v.ID() == yid {
+	return g.hasNeighbor(xid, yid) || g.hasNeighbor(yid, xid)
+}
+
+func (g *AdjacencyList) hasNeighbor(uid, vid int64) bool {
+	for _, v := range g.adjList[uid] {
+		if v.ID() == vid {
 			return true
 		}
 	}
 	return false
 }
 
-// part of the graph.Graph interface
+// part of the graph.Graph interface. The edge goes from u to v no matter which
+// direction it was added in.
 func (g *AdjacencyList) Edge(uid, vid int64) graph.Edge {
-	u := g.nodes[uid]
-	for _, v := range g.adjList[uid] {
-		if v.ID() == vid {
-			return Link{u, v}
-		}
+	if edge := g.WeightedEdge(uid, vid); edge != nil {
+		return edge
 	}
 	return nil
 }
 
-// part of the graph.Undirect interface
+// part of the graph.Undirected interface
 func (g *AdjacencyList) EdgeBetween(xid, yid int64) graph.Edge {
-	edge := g.Edge(xid, yid)
-	if edge == nil {
-		edge = g.Edge(yid, xid)
+	return g.Edge(xid, yid)
+}
+
+// part of the graph.Weighted interface. The weight comes from EdgeWeight.
+func (g *AdjacencyList) WeightedEdge(uid, vid int64) graph.WeightedEdge {
+	if !g.hasNeighbor(uid, vid) && !g.hasNeighbor(vid, uid) {
+		return nil
 	}
-	return edge
+	return NewWeightedLink(g.nodeOrVertex(uid), g.nodeOrVertex(vid), g.EdgeWeight(uid, vid))
 }
 
-// Return the adjacency matrix
+// part of the graph.WeightedUndirected interface
+func (g *AdjacencyList) WeightedEdgeBetween(xid, yid int64) graph.WeightedEdge {
+	return g.WeightedEdge(xid, yid)
+}
+
+// part of the graph.Weighted interface. A node has a weight of 0 to itself unless
+// it has a self loop. Missing edges have a weight of +Inf so that gonum's path
+// algorithms treat them as impassable.
+func (g *AdjacencyList) Weight(xid, yid int64) (w float64, ok bool) {
+	if g.HasEdgeBetween(xid, yid) {
+		return g.EdgeWeight(xid, yid), true
+	}
+	if xid == yid {
+		return 0, true
+	}
+	return math.Inf(1), false
+}
+
+// Return the weight of the edge between u and v. Edges have a weight of 1
+// unless SetEdgeWeight was called. The adjacency matrix ignores weights.
+func (g *AdjacencyList) EdgeWeight(uid, vid int64) float64 {
+	if weight, ok := g.weights[edgeKey(uid, vid)]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (g *AdjacencyList) SetEdgeWeight(uid, vid int64, weight float64) {
+	if weight == 1 {
+		delete(g.weights, edgeKey(uid, vid))
+		return
+	}
+	g.weights[edgeKey(uid, vid)] = weight
+}
+
+func edgeKey(uid, vid int64) [2]int64 {
+	if uid > vid {
+		return [2]int64{vid, uid}
+	}
+	return [2]int64{uid, vid}
+}
+
+// Return the adjacency matrix. Node IDs are used as indices, so they have to be
+// 0 to N-1. It is safe to call from several goroutines. The matrix is never
+// changed, so call M again after adding or removing edges to see the changes.
 func (n *AdjacencyList) M() *mat.Dense {
+	n.cacheLock.Lock()
+	defer n.cacheLock.Unlock()
+	return n.matrix()
+}
+
+// Build the adjacency matrix if needed. The cache lock must be held.
+func (n *AdjacencyList) matrix() *mat.Dense {
 	if n.m == nil {
 		N := int64(n.N())
+		for id := range n.adjList {
+			if id < 0 || id >= N {
+				panic(fmt.Sprintf("node %d can't be used as an index into a %dx%d adjacency matrix", id, N, N))
+			}
+		}
 		backingData := make([]float64, N*N)
 		// u goes from 0 to N-1
 		for uID, neighbors := range n.adjList {
@@ -103,18 +199,32 @@ func (n *AdjacencyList) N() int {
 	return len(n.adjList)
 }
 
+// Return the nodes that are less than distance hops away from the node,
+// including the node itself. It is safe to call from several goroutines.
 func (net *AdjacencyList) NodesWithin(nodeID int64, distance int) sets.IntSet {
-	if net.dm == nil {
-		net.initDistMatrix()
-	}
+	net.cacheLock.Lock()
+	dm := net.distances()
+	net.cacheLock.Unlock()
+	return nodesWithin(dm, nodeID, distance)
+}
+
+// Return the number of hops between u and v, or +Inf if there is no path
+// between them. It is safe to call from several goroutines.
+func (net *AdjacencyList) Distance(uID, vID int64) float64 {
+	net.cacheLock.Lock()
+	dm := net.distances()
+	net.cacheLock.Unlock()
+	return dm.At(int(uID), int(vID))
+}
 
+func nodesWithin(dm *mat.Dense, nodeID int64, distance int) sets.IntSet {
 	maxDist := float64(distance)
 	id := int(nodeID)
-	N, _ := net.m.Dims()
+	N, _ := dm.Dims()
 
 	nodes := sets.EmptyIntSet()
 	for node := 0; node < N; node++ {
-		dist := net.dm.At(id, node)
+		dist := dm.At(id, node)
 		if dist < maxDist {
 			nodes.Add(node)
 		}
@@ -122,10 +232,19 @@ func (net *AdjacencyList) NodesWithin(nodeID int64, distance int) sets.IntSet {
 	return nodes
 }
 
+// Build the distance matrix if needed. The cache lock must be held. The matrix
+// is never changed once it is built, so it can be read without the lock.
+func (net *AdjacencyList) distances() *mat.Dense {
+	if net.dm == nil {
+		net.initDistMatrix()
+	}
+	return net.dm
+}
+
 func (net *AdjacencyList) initDistMatrix() {
-	M := net.m
-	N, _ := net.m.Dims()
-	dm := mat.DenseCopyOf(net.m)
+	M := net.matrix()
+	N, _ := M.Dims()
+	dm := mat.DenseCopyOf(M)
 	dm.Apply(func(i, j int, v float64) float64 {
 		if v < 1 {
 			return math.Inf(1)
@@ -165,3 +284,64 @@ func (net *AdjacencyList) initDistMatrix() {
 
 	net.dm = dm
 }
+
+// part of the graph.Graph interface. Every edge is returned once ordered by
+// the IDs of its endpoints with the smaller ID as the From node. Duplicate edges
+// are returned as many times as they were added.
+func (g *AdjacencyList) Edges() graph.Edges {
+	edges := g.edgeSlice()
+	if len(edges) == 0 {
+		return graph.Empty
+	}
+	return iterator.NewOrderedEdges(edges)
+}
+
+// part of the graph.WeightedUndirected interface. Edges are in the same order as Edges.
+func (g *AdjacencyList) WeightedEdges() graph.WeightedEdges {
+	edges := g.edgeSlice()
+	if len(edges) == 0 {
+		return graph.Empty
+	}
+	weighted := make([]graph.WeightedEdge, len(edges))
+	for i, e := range edges {
+		weighted[i] = e.(graph.WeightedEdge)
+	}
+	return iterator.NewOrderedWeightedEdges(weighted)
+}
+
+func (g *AdjacencyList) edgeSlice() []graph.Edge {
+	ids := make([]int64, 0, len(g.adjList))
+	for id := range g.adjList {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	edges := make([]graph.Edge, 0)
+	for _, uID := range ids {
+		u := g.nodeOrVertex(uID)
+		neighbors := make([]graph.Node, len(g.adjList[uID]))
+		copy(neighbors, g.adjList[uID])
+		sort.SliceStable(neighbors, func(i, j int) bool { return neighbors[i].ID() < neighbors[j].ID() })
+		selfLoopEnds := 0
+		for _, v := range neighbors {
+			if v.ID() > uID {
+				edges = append(edges, NewWeightedLink(u, g.nodeOrVertex(v.ID()), g.EdgeWeight(uID, v.ID())))
+			} else if v.ID() == uID {
+				// both ends of a self loop are stored in u's list
+				selfLoopEnds++
+				if selfLoopEnds%2 == 0 {
+					edges = append(edges, NewWeightedLink(u, u, g.EdgeWeight(uID, uID)))
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// Return the node with the given ID, or a new Vertex if it was never declared.
+func (g *AdjacencyList) nodeOrVertex(id int64) graph.Node {
+	if u, ok := g.nodes[id]; ok {
+		return u
+	}
+	return NewVertex(id)
+}
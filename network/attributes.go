@@ -0,0 +1,104 @@
+package network
+
+import "strconv"
+
+// The key used for a node's community label in GML files
+const CommunityKey = "community"
+
+// A key/value pair attached to a node, such as the label, layout and community
+// entries of a GML node. A key may appear more than once on the same node (GML
+// stores the x and y layout coordinates as two layout entries).
+type Attribute struct {
+	Key   string
+	Value string
+	// true if the value was written as a quoted string rather than a number
+	Quoted bool
+}
+
+// Return all of the attributes of the node in the order they were added.
+func (g *AdjacencyList) Attributes(id int64) []Attribute {
+	return g.attributes[id]
+}
+
+// Return the value of the first attribute of the node with the given key.
+func (g *AdjacencyList) Attribute(id int64, key string) (string, bool) {
+	return firstAttribute(g.attributes[id], key)
+}
+
+func firstAttribute(attrs []Attribute, key string) (string, bool) {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Append attr to the node's attributes. Existing attributes with the same key
+// are kept, so use SetAttribute to replace a value.
+func (g *AdjacencyList) AddAttribute(id int64, attr Attribute) {
+	g.attributes[id] = append(g.attributes[id], attr)
+	g.invalidateView()
+}
+
+// Replace every attribute of the node that has the same key as attr with attr.
+// If the node does not have the key yet, attr is appended.
+func (g *AdjacencyList) SetAttribute(id int64, attr Attribute) {
+	attrs := g.attributes[id]
+	kept := make([]Attribute, 0, len(attrs))
+	replaced := false
+	for _, old := range attrs {
+		if old.Key != attr.Key {
+			kept = append(kept, old)
+		} else if !replaced {
+			kept = append(kept, attr)
+			replaced = true
+		}
+	}
+	if !replaced {
+		kept = append(kept, attr)
+	}
+	g.attributes[id] = kept
+	g.invalidateView()
+}
+
+// Return the community label of each node indexed by node ID. ok is false if
+// any node is missing a community attribute or has a non-integer one.
+func (g *AdjacencyList) Communities() (communities []int, ok bool) {
+	return parseCommunities(g.N(), g.Attribute)
+}
+
+func parseCommunities(N int, attribute func(id int64, key string) (string, bool)) (communities []int, ok bool) {
+	communities = make([]int, N)
+	for id := range communities {
+		value, found := attribute(int64(id), CommunityKey)
+		if !found {
+			return nil, false
+		}
+		community, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, false
+		}
+		communities[id] = community
+	}
+	return communities, true
+}
+
+// Set the community attribute of each node. communities is indexed by node ID,
+// which makes it compatible with the output of Louvain and LabelPropagation.
+func (g *AdjacencyList) SetCommunities(communities []int) {
+	for id, community := range communities {
+		g.SetAttribute(int64(id), Attribute{
+			Key:   CommunityKey,
+			Value: strconv.Itoa(community),
+		})
+	}
+}
+
+// Views hold a copy of the attributes, so they have to be remade after the
+// attributes change.
+func (g *AdjacencyList) invalidateView() {
+	g.cacheLock.Lock()
+	defer g.cacheLock.Unlock()
+	g.view = nil
+}
@@ -0,0 +1,277 @@
+package network
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Return the modularity of the partition of the network described by
+// communities, which holds the community label of each node indexed by node ID.
+func Modularity(net *AdjacencyList, communities []int) float64 {
+	numEdgeEnds := 0
+	// the number of edge ends inside of and touching each community
+	internalEnds := make(map[int]int)
+	degreeSums := make(map[int]int)
+	for uID := 0; uID < net.N(); uID++ {
+		neighbors := net.adjList[int64(uID)]
+		cu := communities[uID]
+		degreeSums[cu] += len(neighbors)
+		numEdgeEnds += len(neighbors)
+		for _, v := range neighbors {
+			if communities[v.ID()] == cu {
+				internalEnds[cu]++
+			}
+		}
+	}
+	if numEdgeEnds == 0 {
+		return 0
+	}
+
+	// sum in a fixed order so that the result doesn't depend on map iteration
+	labels := make([]int, 0, len(degreeSums))
+	for community := range degreeSums {
+		labels = append(labels, community)
+	}
+	sort.Ints(labels)
+	twoM := float64(numEdgeEnds)
+	modularity := 0.0
+	for _, community := range labels {
+		fractionOfDegree := float64(degreeSums[community]) / twoM
+		modularity += float64(internalEnds[community])/twoM - fractionOfDegree*fractionOfDegree
+	}
+	return modularity
+}
+
+// Partition the network into communities using the Louvain method. Nodes are
+// visited in an order drawn from rng, so the same seed always gives the same
+// partition. The returned slice holds the community label of each node indexed
+// by node ID and the labels are numbered from 0 with no gaps.
+func Louvain(net *AdjacencyList, rng *rand.Rand) []int {
+	N := net.N()
+	level := newLouvainGraph(net)
+	// the community of each original node in the current level's graph
+	communities := make([]int, N)
+	for i := range communities {
+		communities[i] = i
+	}
+
+	for {
+		levelCommunities, moved := level.moveNodes(rng)
+		if !moved {
+			break
+		}
+		for i, node := range communities {
+			communities[i] = levelCommunities[node]
+		}
+		level = level.aggregate(levelCommunities)
+	}
+	return relabel(communities)
+}
+
+// Partition the network into communities using asynchronous label propagation.
+// Each node repeatedly adopts the label held by the most of its neighbors with
+// ties broken using rng. The returned slice holds the community label of each
+// node indexed by node ID and the labels are numbered from 0 with no gaps.
+func LabelPropagation(net *AdjacencyList, rng *rand.Rand) []int {
+	N := net.N()
+	labels := make([]int, N)
+	order := make([]int, N)
+	for i := range labels {
+		labels[i] = i
+		order[i] = i
+	}
+
+	// Label propagation is not guaranteed to converge on every network, so give
+	// up after a generous number of sweeps.
+	for sweep := 0; sweep < 100*N+1; sweep++ {
+		rng.Shuffle(N, func(i, j int) { order[i], order[j] = order[j], order[i] })
+		changed := false
+		for _, u := range order {
+			candidates := mostCommonNeighborLabels(net, labels, u)
+			if len(candidates) == 0 || containsInt(candidates, labels[u]) {
+				continue
+			}
+			labels[u] = candidates[rng.Intn(len(candidates))]
+			changed = true
+		}
+		if !changed {
+			break
+		}
+	}
+	return relabel(labels)
+}
+
+// Return the labels that occur the most often among u's neighbors in ascending order.
+func mostCommonNeighborLabels(net *AdjacencyList, labels []int, u int) []int {
+	counts := make(map[int]int)
+	maxCount := 0
+	for _, v := range net.adjList[int64(u)] {
+		label := labels[v.ID()]
+		counts[label]++
+		if counts[label] > maxCount {
+			maxCount = counts[label]
+		}
+	}
+	candidates := make([]int, 0)
+	for label, count := range counts {
+		if count == maxCount {
+			candidates = append(candidates, label)
+		}
+	}
+	sort.Ints(candidates)
+	return candidates
+}
+
+func containsInt(values []int, target int) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Renumber the labels so that they go from 0 to k-1 in the order they first appear.
+func relabel(labels []int) []int {
+	newLabels := make(map[int]int)
+	relabeled := make([]int, len(labels))
+	for i, label := range labels {
+		newLabel, ok := newLabels[label]
+		if !ok {
+			newLabel = len(newLabels)
+			newLabels[label] = newLabel
+		}
+		relabeled[i] = newLabel
+	}
+	return relabeled
+}
+
+type weightedNeighbor struct {
+	node   int
+	weight float64
+}
+
+// A weighted graph where each node is a community from the previous level of
+// the Louvain method. A node's self loop weight counts every internal edge twice
+// so that a node's degree is always the sum of its row.
+type louvainGraph struct {
+	neighbors [][]weightedNeighbor
+	degrees   []float64
+	twoM      float64
+}
+
+func newLouvainGraph(net *AdjacencyList) *louvainGraph {
+	N := net.N()
+	neighbors := make([][]weightedNeighbor, N)
+	for u := range neighbors {
+		for _, v := range net.adjList[int64(u)] {
+			neighbors[u] = append(neighbors[u], weightedNeighbor{int(v.ID()), 1})
+		}
+		sort.Slice(neighbors[u], func(i, j int) bool {
+			return neighbors[u][i].node < neighbors[u][j].node
+		})
+	}
+	return newLouvainGraphFromNeighbors(neighbors)
+}
+
+func newLouvainGraphFromNeighbors(neighbors [][]weightedNeighbor) *louvainGraph {
+	degrees := make([]float64, len(neighbors))
+	twoM := 0.0
+	for u, row := range neighbors {
+		for _, v := range row {
+			degrees[u] += v.weight
+		}
+		twoM += degrees[u]
+	}
+	return &louvainGraph{neighbors, degrees, twoM}
+}
+
+// Greedily move each node into the neighboring community that most increases
+// modularity until no move helps. Return the community of each node and whether
+// any node changed community.
+func (g *louvainGraph) moveNodes(rng *rand.Rand) ([]int, bool) {
+	N := len(g.neighbors)
+	communities := make([]int, N)
+	totals := make([]float64, N)
+	order := make([]int, N)
+	for u := range communities {
+		communities[u] = u
+		totals[u] = g.degrees[u]
+		order[u] = u
+	}
+	if g.twoM == 0 {
+		return communities, false
+	}
+	rng.Shuffle(N, func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+	movedAny := false
+	for moved := true; moved; {
+		moved = false
+		for _, u := range order {
+			oldCommunity := communities[u]
+			// sum the weight from u to each neighboring community, remembering
+			// the order the communities were found in so ties break the same way
+			weightTo := make(map[int]float64)
+			found := make([]int, 0)
+			for _, v := range g.neighbors[u] {
+				if v.node == u {
+					continue
+				}
+				c := communities[v.node]
+				if _, ok := weightTo[c]; !ok {
+					found = append(found, c)
+				}
+				weightTo[c] += v.weight
+			}
+
+			// take u out of its community and find the best one to put it in
+			totals[oldCommunity] -= g.degrees[u]
+			bestCommunity := oldCommunity
+			bestGain := weightTo[oldCommunity] - totals[oldCommunity]*g.degrees[u]/g.twoM
+			for _, c := range found {
+				gain := weightTo[c] - totals[c]*g.degrees[u]/g.twoM
+				if gain > bestGain+1e-12 {
+					bestCommunity = c
+					bestGain = gain
+				}
+			}
+			totals[bestCommunity] += g.degrees[u]
+			if bestCommunity != oldCommunity {
+				communities[u] = bestCommunity
+				moved = true
+				movedAny = true
+			}
+		}
+	}
+	return relabel(communities), movedAny
+}
+
+// Return a new graph with a node for each community.
+func (g *louvainGraph) aggregate(communities []int) *louvainGraph {
+	numCommunities := 0
+	for _, c := range communities {
+		if c+1 > numCommunities {
+			numCommunities = c + 1
+		}
+	}
+	weights := make([]map[int]float64, numCommunities)
+	for c := range weights {
+		weights[c] = make(map[int]float64)
+	}
+	for u, row := range g.neighbors {
+		for _, v := range row {
+			weights[communities[u]][communities[v.node]] += v.weight
+		}
+	}
+
+	neighbors := make([][]weightedNeighbor, numCommunities)
+	for c, row := range weights {
+		for other, weight := range row {
+			neighbors[c] = append(neighbors[c], weightedNeighbor{other, weight})
+		}
+		sort.Slice(neighbors[c], func(i, j int) bool {
+			return neighbors[c][i].node < neighbors[c][j].node
+		})
+	}
+	return newLouvainGraphFromNeighbors(neighbors)
+}
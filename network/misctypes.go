@@ -31,6 +31,26 @@ func (l Link) ReversedEdge() graph.Edge {
 	}
 }
 
+// A Link with a weight. Part of the graph.WeightedEdge interface.
+type WeightedLink struct {
+	Link
+	weight float64
+}
+
+func NewWeightedLink(src, dest graph.Node, weight float64) WeightedLink {
+	return WeightedLink{Link{src, dest}, weight}
+}
+
+// part of the graph.WeightedEdge interface
+func (l WeightedLink) Weight() float64 {
+	return l.weight
+}
+
+// part of the graph.Edge interface
+func (l WeightedLink) ReversedEdge() graph.Edge {
+	return WeightedLink{Link{l.dest, l.src}, l.weight}
+}
+
 type Vertex int
 
 func NewVertex(id int64) Vertex {
@@ -42,28 +62,53 @@ func (u Vertex) ID() int64 {
 	return int64(u)
 }
 
-// Adheres to the graph.Nodes interface which is an interface for interating across nodes.
+// Adheres to the graph.Nodes and graph.NodeSlicer interfaces which are for
+// iterating across nodes. Next has to be called before the first call to Node.
 type SliceIterator struct {
-	nodes        []graph.Node
+	nodes []graph.Node
+	// the index of the current node, which is -1 before the first call to Next
 	currentIndex int
 }
 
+func NewSliceIterator(nodes []graph.Node) *SliceIterator {
+	return &SliceIterator{nodes, -1}
+}
+
 func (v *SliceIterator) Node() graph.Node {
-	if v.currentIndex >= len(v.nodes) {
+	if v.currentIndex < 0 || v.currentIndex >= len(v.nodes) {
 		return nil
 	}
-	v.currentIndex++
-	return v.nodes[v.currentIndex-1]
+	return v.nodes[v.currentIndex]
 }
 
 func (v *SliceIterator) Next() bool {
+	if v.currentIndex >= len(v.nodes) {
+		return false
+	}
+	v.currentIndex++
 	return v.currentIndex < len(v.nodes)
 }
 
+// Return the number of nodes that Next hasn't reached yet.
 func (v *SliceIterator) Len() int {
-	return len(v.nodes) - v.currentIndex
+	if v.currentIndex >= len(v.nodes) {
+		return 0
+	}
+	return len(v.nodes) - v.currentIndex - 1
 }
 
 func (v *SliceIterator) Reset() {
-	v.currentIndex = 0
+	v.currentIndex = -1
+}
+
+// part of the graph.NodeSlicer interface. Returns the nodes that Next hasn't
+// reached yet and moves to the end.
+func (v *SliceIterator) NodeSlice() []graph.Node {
+	if v.currentIndex >= len(v.nodes) {
+		return nil
+	}
+	remaining := make([]graph.Node, len(v.nodes)-v.currentIndex-1)
+	copy(remaining, v.nodes[v.currentIndex+1:])
+	v.currentIndex = len(v.nodes)
+	return remaining
 }
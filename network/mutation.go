@@ -0,0 +1,136 @@
+package network
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// Add a node without any edges. It panics if a node with the same ID already
+// exists. The adjacency matrix is rebuilt the next time M is called, so the new
+// node's ID must keep the IDs in 0 to N-1 before M can be used.
+func (g *AdjacencyList) AddNode(u graph.Node) {
+	if _, ok := g.nodes[u.ID()]; ok {
+		panic(fmt.Sprintf("node %d already exists", u.ID()))
+	}
+	g.nodes[u.ID()] = u
+	g.adjList[u.ID()] = make([]graph.Node, 0)
+	g.invalidateMatrices()
+}
+
+// Remove a node along with its edges, edge weights and attributes. The IDs of
+// the other nodes don't change, so M can't be used until the gap is filled by
+// adding a node with the removed ID.
+func (g *AdjacencyList) RemoveNode(id int64) {
+	if _, ok := g.nodes[id]; !ok {
+		panic(fmt.Sprintf("node %d does not exist", id))
+	}
+	for _, v := range g.adjList[id] {
+		if v.ID() != id {
+			g.adjList[v.ID()] = withoutNeighbor(g.adjList[v.ID()], id)
+		}
+		delete(g.weights, edgeKey(id, v.ID()))
+	}
+	delete(g.nodes, id)
+	delete(g.adjList, id)
+	delete(g.attributes, id)
+	g.invalidateMatrices()
+}
+
+// Add an edge with a weight of 1 between u and v. Both nodes must already exist.
+// Returns false without changing anything if the edge is already there or if u
+// and v are the same node, since self loops aren't allowed.
+func (g *AdjacencyList) AddEdge(uid, vid int64) bool {
+	u, v := g.mustNode(uid), g.mustNode(vid)
+	if uid == vid || g.hasNeighbor(uid, vid) {
+		return false
+	}
+	g.adjList[uid] = append(g.adjList[uid], v)
+	g.adjList[vid] = append(g.adjList[vid], u)
+	g.invalidateMatrices()
+	return true
+}
+
+// Remove the edge between u and v along with its weight. Duplicates of the edge
+// are removed as well. Returns false if there was no edge.
+func (g *AdjacencyList) RemoveEdge(uid, vid int64) bool {
+	if !g.hasNeighbor(uid, vid) {
+		return false
+	}
+	g.adjList[uid] = withoutNeighbor(g.adjList[uid], vid)
+	if uid != vid {
+		g.adjList[vid] = withoutNeighbor(g.adjList[vid], uid)
+	}
+	delete(g.weights, edgeKey(uid, vid))
+	g.invalidateMatrices()
+	return true
+}
+
+// Move the end of the edge between u and v from v to w so that it connects u
+// and w instead. The edge keeps its weight. It panics if there is no edge
+// between u and v, and returns false without changing anything if u and w are
+// already connected or if the edge is or would become a self loop.
+func (g *AdjacencyList) RewireEdge(uid, vid, wid int64) bool {
+	if !g.hasNeighbor(uid, vid) {
+		panic(fmt.Sprintf("there is no edge between %d and %d", uid, vid))
+	}
+	g.mustNode(wid)
+	if uid == vid || uid == wid || g.hasNeighbor(uid, wid) {
+		return false
+	}
+	weight := g.EdgeWeight(uid, vid)
+	g.RemoveEdge(uid, vid)
+	g.AddEdge(uid, wid)
+	g.SetEdgeWeight(uid, wid, weight)
+	return true
+}
+
+func (g *AdjacencyList) mustNode(id int64) graph.Node {
+	u, ok := g.nodes[id]
+	if !ok {
+		panic(fmt.Sprintf("node %d does not exist", id))
+	}
+	return u
+}
+
+// Throw away the cached matrices so that they are rebuilt the next time they are
+// needed. Matrices that were already returned are left alone since other
+// goroutines may be reading them.
+func (g *AdjacencyList) invalidateMatrices() {
+	g.cacheLock.Lock()
+	defer g.cacheLock.Unlock()
+	g.m = nil
+	g.dm = nil
+	g.view = nil
+}
+
+func withoutNeighbor(neighbors []graph.Node, id int64) []graph.Node {
+	kept := make([]graph.Node, 0, len(neighbors))
+	for _, v := range neighbors {
+		if v.ID() != id {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// Return a deep copy of the network that can be changed without affecting the
+// original. Nodes are shared since they are immutable.
+func (g *AdjacencyList) Copy() *AdjacencyList {
+	nodes := make([]graph.Node, 0, len(g.nodes))
+	for _, u := range g.nodes {
+		nodes = append(nodes, u)
+	}
+	adjList := make(map[int64][]graph.Node, len(g.adjList))
+	for id, neighbors := range g.adjList {
+		adjList[id] = append(make([]graph.Node, 0, len(neighbors)), neighbors...)
+	}
+	c := NewAdjacencyList(nodes, adjList)
+	for id, attrs := range g.attributes {
+		c.attributes[id] = append(make([]Attribute, 0, len(attrs)), attrs...)
+	}
+	for key, weight := range g.weights {
+		c.weights[key] = weight
+	}
+	return c
+}
@@ -0,0 +1,110 @@
+package network
+
+import (
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// Return a copy of the network with numSwaps double edge swaps applied. A swap
+// takes edges a-b and c-d and replaces them with a-d and c-b, so every node keeps
+// its degree. Swaps that would make a self loop or a duplicate edge are skipped.
+// The copy keeps the network's attributes, and each edge's weight stays with the
+// node it was swapped away from. After 100*numSwaps attempts it gives up, so
+// fewer swaps may be made on networks with very little room to rewire.
+func DegreePreservingRewire(net *AdjacencyList, numSwaps int, rng *rand.Rand) *AdjacencyList {
+	return swapEdges(net, numSwaps, rng, func(a, b, c, d int64) bool { return true })
+}
+
+// Like DegreePreservingRewire, but edges are only swapped when a and c are in the
+// same community and b and d are in the same community, so each node keeps the
+// number of edges it has into each community. Communities are read from the
+// community attribute of each node, which must be set on every node.
+func CommunityPreservingRewire(net *AdjacencyList, numSwaps int, rng *rand.Rand) *AdjacencyList {
+	communities, ok := net.Communities()
+	if !ok {
+		panic("every node needs an integer community attribute to preserve communities")
+	}
+	return swapEdges(net, numSwaps, rng, func(a, b, c, d int64) bool {
+		return communities[a] == communities[c] && communities[b] == communities[d]
+	})
+}
+
+func swapEdges(net *AdjacencyList, numSwaps int, rng *rand.Rand, canSwap func(a, b, c, d int64) bool) *AdjacencyList {
+	rewired := net.Copy()
+	edges := make([][2]int64, 0)
+	for _, e := range rewired.edgeSlice() {
+		if e.From().ID() != e.To().ID() {
+			edges = append(edges, [2]int64{e.From().ID(), e.To().ID()})
+		}
+	}
+	if len(edges) < 2 {
+		return rewired
+	}
+
+	maxTries := 100 * numSwaps
+	for swaps, tries := 0, 0; swaps < numSwaps && tries < maxTries; tries++ {
+		i, j := rng.Intn(len(edges)), rng.Intn(len(edges))
+		if i == j {
+			continue
+		}
+		// pick which end of each edge is swapped at random
+		a, b := edges[i][0], edges[i][1]
+		if rng.Intn(2) == 0 {
+			a, b = b, a
+		}
+		c, d := edges[j][0], edges[j][1]
+		if rng.Intn(2) == 0 {
+			c, d = d, c
+		}
+		if a == c || a == d || b == c || b == d || !canSwap(a, b, c, d) {
+			continue
+		}
+		if rewired.HasEdgeBetween(a, d) || rewired.HasEdgeBetween(c, b) {
+			continue
+		}
+		rewired.RewireEdge(a, b, d)
+		rewired.RewireEdge(c, d, b)
+		edges[i] = [2]int64{a, d}
+		edges[j] = [2]int64{c, b}
+		swaps++
+	}
+	return rewired
+}
+
+// Return a random network with the same nodes and attributes as net where the
+// edges are drawn from the configuration model. Every node gets as many edge
+// stubs as its degree in net and the stubs are paired up at random. Pairs that
+// would make self loops or duplicate edges are dropped, so nodes can end up with
+// a slightly lower degree than they had in net. Edge weights are not kept.
+func ConfigurationModel(net *AdjacencyList, rng *rand.Rand) *AdjacencyList {
+	ids := make([]int64, 0, len(net.nodes))
+	for id := range net.nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	nodes := make([]graph.Node, len(ids))
+	adjList := make(map[int64][]graph.Node, len(ids))
+	stubs := make([]int64, 0)
+	for i, id := range ids {
+		nodes[i] = net.nodes[id]
+		adjList[id] = make([]graph.Node, 0)
+		for range net.adjList[id] {
+			stubs = append(stubs, id)
+		}
+	}
+	rng.Shuffle(len(stubs), func(i, j int) { stubs[i], stubs[j] = stubs[j], stubs[i] })
+
+	resampled := NewAdjacencyList(nodes, adjList)
+	for i := 0; i+1 < len(stubs); i += 2 {
+		if stubs[i] != stubs[i+1] {
+			resampled.AddEdge(stubs[i], stubs[i+1])
+		}
+	}
+	for id, attrs := range net.attributes {
+		resampled.attributes[id] = append(make([]Attribute, 0, len(attrs)), attrs...)
+	}
+	return resampled
+}
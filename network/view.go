@@ -0,0 +1,83 @@
+package network
+
+import (
+	"github.com/GaudiestTooth17/irn-sim/sets"
+	"gonum.org/v1/gonum/mat"
+)
+
+// The read only parts of a network that simulations and behaviors use. Both
+// *AdjacencyList and *View implement it.
+type Topology interface {
+	N() int
+	M() *mat.Dense
+	NodesWithin(nodeID int64, distance int) sets.IntSet
+	Distance(uID, vID int64) float64
+}
+
+// An immutable copy of a network's adjacency and distance matrices and node
+// attributes. Everything is computed when the View is made, so it can be read
+// from any number of goroutines without locking. This lets many simulations on
+// the same network share one copy of each matrix.
+type View struct {
+	m          *mat.Dense
+	dm         *mat.Dense
+	attributes map[int64][]Attribute
+}
+
+// Return a View of the network as it is now. The same View is returned until
+// the network is changed. Node IDs have to be 0 to N-1.
+func (net *AdjacencyList) View() *View {
+	net.cacheLock.Lock()
+	defer net.cacheLock.Unlock()
+	if net.view == nil {
+		attributes := make(map[int64][]Attribute, len(net.attributes))
+		for id, attrs := range net.attributes {
+			attributes[id] = append(make([]Attribute, 0, len(attrs)), attrs...)
+		}
+		net.view = &View{
+			m:          mat.DenseCopyOf(net.matrix()),
+			dm:         net.distances(),
+			attributes: attributes,
+		}
+	}
+	return net.view
+}
+
+// Return the number of nodes in the network
+func (v *View) N() int {
+	N, _ := v.m.Dims()
+	return N
+}
+
+// Return the adjacency matrix. It is shared by every user of the View, so it
+// must not be modified.
+func (v *View) M() *mat.Dense {
+	return v.m
+}
+
+// Return the nodes that are less than distance hops away from the node,
+// including the node itself.
+func (v *View) NodesWithin(nodeID int64, distance int) sets.IntSet {
+	return nodesWithin(v.dm, nodeID, distance)
+}
+
+// Return the number of hops between u and v, or +Inf if there is no path between them.
+func (v *View) Distance(uID, vID int64) float64 {
+	return v.dm.At(int(uID), int(vID))
+}
+
+func (v *View) HasEdgeBetween(xid, yid int64) bool {
+	return v.m.At(int(xid), int(yid)) == 1
+}
+
+// Return the value of the first attribute of the node with the given key as it
+// was when the View was made.
+func (v *View) Attribute(id int64, key string) (string, bool) {
+	return firstAttribute(v.attributes[id], key)
+}
+
+// Return the community labels the network had when the View was made. ok is
+// false if the network didn't have them.
+func (v *View) Communities() (communities []int, ok bool) {
+	return parseCommunities(v.N(), v.Attribute)
+}
@@ -0,0 +1,150 @@
+package notation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The type of a value
+type Kind int
+
+const (
+	IntValue Kind = iota
+	FloatValue
+	TupleValue
+	StringValue
+)
+
+// A value such as the ib=(5, 10) in ConnComm(N_comm=10,ib=(5, 10),num_comms=50,ob=(3, 6))
+type Value struct {
+	// the key the value was given for, which is empty for tuple elements
+	Name string
+	Kind Kind
+	// only set for IntValues
+	Int int
+	// set for both IntValues and FloatValues
+	Float float64
+	// the elements of a TupleValue
+	Tuple []Value
+	// the value as it was written
+	Raw string
+}
+
+// A name and the values given for its keys
+type Call struct {
+	Name string
+	Args []Value
+}
+
+// Parse a string of the form Name(key=value,key=value,...), which is how both
+// class names and behavior strings are written. A name without
+// parentheses has no arguments. Values can be ints, floats, tuples of values in
+// parentheses, or anything else, which is kept as a string.
+func Parse(s string) (Call, error) {
+	s = strings.TrimSpace(s)
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return Call{Name: s, Args: []Value{}}, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return Call{}, fmt.Errorf("%s is missing its closing parenthesis", s)
+	}
+	call := Call{Name: s[:open], Args: []Value{}}
+
+	argsStr := s[open+1 : len(s)-1]
+	if strings.TrimSpace(argsStr) == "" {
+		return call, nil
+	}
+	parts, err := splitTopLevel(argsStr)
+	if err != nil {
+		return Call{}, err
+	}
+	for _, part := range parts {
+		equals := strings.IndexByte(part, '=')
+		if equals < 0 {
+			return Call{}, fmt.Errorf("parameter '%s' has no value", part)
+		}
+		arg, err := ParseValue(part[equals+1:])
+		if err != nil {
+			return Call{}, err
+		}
+		arg.Name = strings.TrimSpace(part[:equals])
+		call.Args = append(call.Args, arg)
+	}
+	return call, nil
+}
+
+// Parse a single value, such as 3, 0.5 or (5, 10). The returned value has no name.
+func ParseValue(raw string) (Value, error) {
+	raw = strings.TrimSpace(raw)
+	value := Value{Raw: raw}
+	if strings.HasPrefix(raw, "(") {
+		if !strings.HasSuffix(raw, ")") {
+			return value, fmt.Errorf("tuple %s is missing its closing parenthesis", raw)
+		}
+		value.Kind = TupleValue
+		value.Tuple = []Value{}
+		inner := raw[1 : len(raw)-1]
+		if strings.TrimSpace(inner) == "" {
+			return value, nil
+		}
+		elements, err := splitTopLevel(inner)
+		if err != nil {
+			return value, err
+		}
+		for _, element := range elements {
+			// Python writes single element tuples as (x,)
+			if strings.TrimSpace(element) == "" {
+				continue
+			}
+			elementValue, err := ParseValue(element)
+			if err != nil {
+				return value, err
+			}
+			value.Tuple = append(value.Tuple, elementValue)
+		}
+		return value, nil
+	}
+	if i, err := strconv.Atoi(raw); err == nil {
+		value.Kind = IntValue
+		value.Int = i
+		value.Float = float64(i)
+		return value, nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		value.Kind = FloatValue
+		value.Float = f
+		return value, nil
+	}
+	value.Kind = StringValue
+	value.Raw = strings.Trim(raw, `"'`)
+	return value, nil
+}
+
+// Split s on the commas that aren't inside of parentheses.
+func splitTopLevel(s string) ([]string, error) {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in '%s'", s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in '%s'", s)
+	}
+	return append(parts, s[start:]), nil
+}
@@ -0,0 +1,175 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/mat"
+)
+
+// A behavior that records statistics about each step of a simulation
+type ReportingBehavior interface {
+	Behavior
+	// Return the statistics recorded since the last Reset keyed by name. Entry i
+	// of each statistic is for time step i+1.
+	Report() map[string][]float64
+}
+
+// Who a susceptible agent connects to after cutting an infectious neighbor
+type RewireTarget int
+
+const (
+	// any susceptible agent
+	RewireToRandom RewireTarget = iota
+	// a susceptible neighbor of one of its neighbors. Agents that don't have one
+	// keep their edge to the infectious agent.
+	RewireToFriendOfFriend
+)
+
+func (t RewireTarget) String() string {
+	switch t {
+	case RewireToRandom:
+		return "random"
+	case RewireToFriendOfFriend:
+		return "friend_of_friend"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by RewireTarget.String.
+func ParseRewireTarget(name string) (RewireTarget, error) {
+	for _, target := range []RewireTarget{RewireToRandom, RewireToFriendOfFriend} {
+		if target.String() == name {
+			return target, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rewire target %s", name)
+}
+
+// Each step, every susceptible agent cuts each of its edges to infectious agents
+// with probability rate and connects to a new susceptible agent instead, so the
+// number of edges never changes. Unlike the pressure behaviors the changes last
+// for the rest of the simulation. They are made to a copy of the network's
+// adjacency matrix that belongs to the current simulation, so the network itself
+// isn't changed and Reset starts over from the original edges.
+type AdaptiveRewiringBehavior struct {
+	net    network.Topology
+	rate   float64
+	target RewireTarget
+	rng    *rand.Rand
+	// the edges as they are after the rewiring so far
+	current *mat.Dense
+	// the number of edges rewired at each step
+	rewirings []float64
+}
+
+func NewAdaptiveRewiringBehavior(net network.Topology,
+	rng *rand.Rand,
+	rate float64,
+	target RewireTarget) *AdaptiveRewiringBehavior {
+
+	b := &AdaptiveRewiringBehavior{net: net, rate: rate, target: target, rng: rng}
+	b.Reset()
+	return b
+}
+
+func (b *AdaptiveRewiringBehavior) Name() string {
+	return fmt.Sprintf("AdaptiveRewiring(rate=%f, target=%s)", b.rate, b.target)
+}
+
+// Undo all of the rewiring and clear the report.
+func (b *AdaptiveRewiringBehavior) Reset() {
+	b.current = mat.DenseCopyOf(b.net.M())
+	b.rewirings = make([]float64, 0)
+}
+
+func (b *AdaptiveRewiringBehavior) Report() map[string][]float64 {
+	rewirings := make([]float64, len(b.rewirings))
+	copy(rewirings, b.rewirings)
+	return map[string][]float64{"rewirings": rewirings}
+}
+
+func (b *AdaptiveRewiringBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	N, _ := b.current.Dims()
+	// find every edge between a susceptible and an infectious agent before
+	// changing anything so that new edges aren't considered until the next step
+	type sirEdge struct{ s, i int }
+	atRisk := make([]sirEdge, 0)
+	susceptible := make([]int, 0)
+	for s := 0; s < N; s++ {
+		if sir.S[s] <= 0 {
+			continue
+		}
+		susceptible = append(susceptible, s)
+		for i := 0; i < N; i++ {
+			if sir.I[i] > 0 && b.current.At(s, i) == 1 {
+				atRisk = append(atRisk, sirEdge{s, i})
+			}
+		}
+	}
+
+	numRewired := 0
+	for _, e := range atRisk {
+		if b.rng.Float64() >= b.rate {
+			continue
+		}
+		var newNeighbor int
+		var ok bool
+		switch b.target {
+		case RewireToRandom:
+			newNeighbor, ok = b.randomTarget(e.s, susceptible)
+		case RewireToFriendOfFriend:
+			newNeighbor, ok = b.friendOfFriendTarget(e.s, sir)
+		}
+		if !ok {
+			continue
+		}
+		b.setEdge(e.s, e.i, 0)
+		b.setEdge(e.s, newNeighbor, 1)
+		numRewired++
+	}
+	b.rewirings = append(b.rewirings, float64(numRewired))
+	return b.current
+}
+
+// Pick a susceptible agent that s isn't connected to.
+func (b *AdaptiveRewiringBehavior) randomTarget(s int, susceptible []int) (int, bool) {
+	candidates := make([]int, 0, len(susceptible))
+	for _, t := range susceptible {
+		if t != s && b.current.At(s, t) == 0 {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	return candidates[b.rng.Intn(len(candidates))], true
+}
+
+// Pick a susceptible neighbor of a neighbor of s that s isn't connected to.
+func (b *AdaptiveRewiringBehavior) friendOfFriendTarget(s int, sir SIR) (int, bool) {
+	N, _ := b.current.Dims()
+	isCandidate := make([]bool, N)
+	candidates := make([]int, 0)
+	for friend := 0; friend < N; friend++ {
+		if b.current.At(s, friend) == 0 {
+			continue
+		}
+		for t := 0; t < N; t++ {
+			if t != s && !isCandidate[t] && sir.S[t] > 0 && b.current.At(friend, t) == 1 && b.current.At(s, t) == 0 {
+				isCandidate[t] = true
+				candidates = append(candidates, t)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	return candidates[b.rng.Intn(len(candidates))], true
+}
+
+func (b *AdaptiveRewiringBehavior) setEdge(u, v int, value float64) {
+	b.current.Set(u, v, value)
+	b.current.Set(v, u, value)
+}
@@ -13,65 +13,84 @@ import (
 type Behavior interface {
 	Name() string
 	UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense
+	// Clear any state left over from a previous simulation. Simulate calls it
+	// before the first step so that replicates using the same behavior don't
+	// affect each other.
+	Reset()
 }
 
+// Which edges a flickering agent turns off
+type FlickerMode int
+
+const (
+	// every edge of a flickering agent
+	FlickerAllEdges FlickerMode = iota
+	// only the edges between two flickering agents
+	FlickerBetweenFlickering
+)
+
+func (m FlickerMode) String() string {
+	switch m {
+	case FlickerAllEdges:
+		return "all"
+	case FlickerBetweenFlickering:
+		return "pairs"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by FlickerMode.String.
+func ParseFlickerMode(name string) (FlickerMode, error) {
+	for _, mode := range []FlickerMode{FlickerAllEdges, FlickerBetweenFlickering} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown flicker mode %s", name)
+}
+
+// Agents within radius of an agent are pressured from when it becomes
+// infectious until it is removed. Each step, every pressured agent flickers with
+// flickerProbability.
 type SimplePressureBehavior struct {
 	radius             int
-	net                *network.AdjacencyList
+	net                network.Topology
 	pressure           []float64
 	flickerProbability float64
+	flickerMode        FlickerMode
 	rng                *rand.Rand
 }
 
-func NewSimplePressureBehavior(net *network.AdjacencyList,
+func NewSimplePressureBehavior(net network.Topology,
 	rng *rand.Rand,
 	radius int,
-	flickerProbability float64) SimplePressureBehavior {
+	flickerProbability float64,
+	flickerMode FlickerMode) SimplePressureBehavior {
 
 	return SimplePressureBehavior{
 		radius:             radius,
 		net:                net,
 		pressure:           make([]float64, net.N()),
 		flickerProbability: flickerProbability,
+		flickerMode:        flickerMode,
 		rng:                rng,
 	}
 }
 
 func (b SimplePressureBehavior) Name() string {
-	return fmt.Sprintf("SimplePressure(radius=%d, flicker_probability=%f)",
-		b.radius, b.flickerProbability)
+	return fmt.Sprintf("SimplePressure(radius=%d, flicker_probability=%f, flicker_mode=%s)",
+		b.radius, b.flickerProbability, b.flickerMode)
 }
 
-func (b SimplePressureBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
-	infectiousAgents := sir.InfectiousAgents()
-	if len(infectiousAgents) > 0 {
-		// populate pressuredAgents by finding all the agents in pressure range
-		// of the infectious agents
-		pressuredAgents := sets.EmptyIntSet()
-		for agent := range infectiousAgents {
-			agentsInRadius := b.net.NodesWithin(int64(agent), b.radius)
-			pressuredAgents = pressuredAgents.Union(agentsInRadius)
-		}
-		// apply pressure
-		for agent := range pressuredAgents {
-			b.pressure[agent] += 1
-		}
+// Remove all pressure from the agents.
+func (b SimplePressureBehavior) Reset() {
+	for agent := range b.pressure {
+		b.pressure[agent] = 0
 	}
+}
 
-	recoveredAgents := sir.RemovedAgents()
-	if len(recoveredAgents) > 0 {
-		// populate unpressuredAgents by finding all the agents in pressure range
-		// of the recovered agents
-		unpressuredAgents := sets.EmptyIntSet()
-		for agent := range recoveredAgents {
-			agentsInRange := b.net.NodesWithin(int64(agent), b.radius)
-			unpressuredAgents = unpressuredAgents.Union(agentsInRange)
-		}
-		// relieve pressure
-		for agent := range unpressuredAgents {
-			b.pressure[agent] -= 1
-		}
-	}
+func (b SimplePressureBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	updateRadiusPressure(b.net, b.radius, b.pressure, sir)
 
 	// for all the agents currently experiencing pressure, get a random value
 	// to determine if they will flicker
@@ -82,12 +101,41 @@ func (b SimplePressureBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, ti
 		}
 	}
 
+	return removeFlickeringEdges(M, flickeringAgents, b.flickerMode)
+}
+
+// Each infectious agent adds one unit of pressure to the agents within radius
+// when it becomes infectious and takes it away when it is removed, so pressure
+// counts the infectious agents in range.
+func updateRadiusPressure(net network.Topology, radius int, pressure []float64, sir SIR) {
+	for agent := range sir.NewlyInfectiousAgents() {
+		for pressured := range net.NodesWithin(int64(agent), radius) {
+			pressure[pressured] += 1
+		}
+	}
+	for agent := range sir.NewlyRemovedAgents() {
+		for relieved := range net.NodesWithin(int64(agent), radius) {
+			pressure[relieved] -= 1
+		}
+	}
+}
+
+// Return a copy of M with the edges of the flickering agents turned off.
+func removeFlickeringEdges(M *mat.Dense, flickeringAgents sets.IntSet, mode FlickerMode) *mat.Dense {
 	R := mat.DenseCopyOf(M)
-	// turn off the edges connected to a flickering agent
+	N, _ := R.Dims()
 	for agent0 := range flickeringAgents {
-		for agent1 := range flickeringAgents {
-			R.Set(agent0, agent1, 0)
-			R.Set(agent1, agent0, 0)
+		switch mode {
+		case FlickerAllEdges:
+			for agent1 := 0; agent1 < N; agent1++ {
+				R.Set(agent0, agent1, 0)
+				R.Set(agent1, agent0, 0)
+			}
+		case FlickerBetweenFlickering:
+			for agent1 := range flickeringAgents {
+				R.Set(agent0, agent1, 0)
+				R.Set(agent1, agent0, 0)
+			}
 		}
 	}
 	return R
@@ -99,6 +147,8 @@ func (b StaticBehavior) Name() string {
 	return "StaticBehavior"
 }
 
+func (b StaticBehavior) Reset() {}
+
 func (b StaticBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
 	return M
 }
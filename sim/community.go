@@ -0,0 +1,173 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Keeps track of which community each agent is in and records how the disease
+// does in each community.
+type communityTracker struct {
+	// the community of each agent
+	communities []int
+	// the distinct community labels in increasing order
+	labels  []int
+	sizes   map[int]int
+	reports map[string][]float64
+}
+
+func newCommunityTracker(net network.Topology, communities []int) communityTracker {
+	if len(communities) != net.N() {
+		panic(fmt.Sprintf("there are %d community labels for %d agents", len(communities), net.N()))
+	}
+	t := communityTracker{communities: communities, sizes: make(map[int]int)}
+	for _, community := range communities {
+		if t.sizes[community] == 0 {
+			t.labels = append(t.labels, community)
+		}
+		t.sizes[community]++
+	}
+	sort.Ints(t.labels)
+	t.reset()
+	return t
+}
+
+func (t *communityTracker) reset() {
+	t.reports = make(map[string][]float64)
+}
+
+// Return the number of infectious agents in each community.
+func (t *communityTracker) infectious(sir SIR) map[int]int {
+	counts := make(map[int]int)
+	for agent := range sir.InfectiousAgents() {
+		counts[t.communities[agent]]++
+	}
+	return counts
+}
+
+// Record the number of infectious and removed agents in each community.
+func (t *communityTracker) record(sir SIR) {
+	infectious := t.infectious(sir)
+	removed := make(map[int]int)
+	for agent, r := range sir.R {
+		if r > 0 {
+			removed[t.communities[agent]]++
+		}
+	}
+	for _, community := range t.labels {
+		t.add(community, "infectious", float64(infectious[community]))
+		t.add(community, "removed", float64(removed[community]))
+	}
+}
+
+// Append value to the statistic for the community.
+func (t *communityTracker) add(community int, statistic string, value float64) {
+	key := fmt.Sprintf("community_%d_%s", community, statistic)
+	t.reports[key] = append(t.reports[key], value)
+}
+
+func (t *communityTracker) report() map[string][]float64 {
+	report := make(map[string][]float64, len(t.reports))
+	for key, values := range t.reports {
+		report[key] = make([]float64, len(values))
+		copy(report[key], values)
+	}
+	return report
+}
+
+// Return a copy of M without the edges between different communities that
+// touch a community for which cut is true.
+func (t *communityTracker) cutBetweenCommunities(M *mat.Dense, cut func(community int) bool) *mat.Dense {
+	D := mat.DenseCopyOf(M)
+	N, _ := M.Dims()
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			cu, cv := t.communities[u], t.communities[v]
+			if M.At(u, v) == 1 && cu != cv && (cut(cu) || cut(cv)) {
+				D.Set(u, v, 0)
+				D.Set(v, u, 0)
+			}
+		}
+	}
+	return D
+}
+
+// Cuts every edge between a community and the rest of the network while more
+// than a fraction of the community is infectious. A prevalence of 0 isolates a
+// community as soon as any of its agents are infectious. Each community's
+// infectious and removed counts and whether it was isolated are reported for
+// each step.
+type CommunityIsolationBehavior struct {
+	tracker    communityTracker
+	prevalence float64
+}
+
+func NewCommunityIsolationBehavior(net network.Topology, communities []int, prevalence float64) *CommunityIsolationBehavior {
+	return &CommunityIsolationBehavior{tracker: newCommunityTracker(net, communities), prevalence: prevalence}
+}
+
+func (b *CommunityIsolationBehavior) Name() string {
+	return fmt.Sprintf("CommunityIsolation(prevalence=%f)", b.prevalence)
+}
+
+func (b *CommunityIsolationBehavior) Reset() {
+	b.tracker.reset()
+}
+
+func (b *CommunityIsolationBehavior) Report() map[string][]float64 {
+	return b.tracker.report()
+}
+
+func (b *CommunityIsolationBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	b.tracker.record(sir)
+	infectious := b.tracker.infectious(sir)
+	isolated := make(map[int]bool)
+	for _, community := range b.tracker.labels {
+		if float64(infectious[community])/float64(b.tracker.sizes[community]) > b.prevalence {
+			isolated[community] = true
+			b.tracker.add(community, "isolated", 1)
+		} else {
+			b.tracker.add(community, "isolated", 0)
+		}
+	}
+	if len(isolated) == 0 {
+		return M
+	}
+	return b.tracker.cutBetweenCommunities(M, func(community int) bool { return isolated[community] })
+}
+
+// Every agent only keeps the edges inside its community for duration steps
+// starting at time step start. Each community's infectious and removed counts
+// are reported for each step.
+type BubbleBehavior struct {
+	tracker  communityTracker
+	start    int
+	duration int
+}
+
+func NewBubbleBehavior(net network.Topology, communities []int, start, duration int) *BubbleBehavior {
+	return &BubbleBehavior{tracker: newCommunityTracker(net, communities), start: start, duration: duration}
+}
+
+func (b *BubbleBehavior) Name() string {
+	return fmt.Sprintf("Bubble(start=%d, duration=%d)", b.start, b.duration)
+}
+
+func (b *BubbleBehavior) Reset() {
+	b.tracker.reset()
+}
+
+func (b *BubbleBehavior) Report() map[string][]float64 {
+	return b.tracker.report()
+}
+
+func (b *BubbleBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	b.tracker.record(sir)
+	if timeStep < b.start || timeStep >= b.start+b.duration {
+		return M
+	}
+	return b.tracker.cutBetweenCommunities(M, func(int) bool { return true })
+}
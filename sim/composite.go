@@ -0,0 +1,124 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// How a CompositeBehavior combines the edges its behaviors keep
+type CompositeMode int
+
+const (
+	// every behavior sees the whole network and an edge is only kept if all of
+	// them keep it. Only edges of M can be kept, so the edges that behaviors like
+	// AdaptiveRewiringBehavior add are dropped. Use SequenceBehaviors with such a
+	// behavior last to keep them.
+	IntersectBehaviors CompositeMode = iota
+	// each behavior is given the edges kept by the one before it in place of M.
+	// Behaviors that keep their own copy of the network, like
+	// AdaptiveRewiringBehavior, ignore what came before them.
+	SequenceBehaviors
+)
+
+func (m CompositeMode) String() string {
+	switch m {
+	case IntersectBehaviors:
+		return "intersection"
+	case SequenceBehaviors:
+		return "sequence"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by CompositeMode.String.
+func ParseCompositeMode(name string) (CompositeMode, error) {
+	for _, mode := range []CompositeMode{IntersectBehaviors, SequenceBehaviors} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown composite mode %s", name)
+}
+
+// Several behaviors acting on the same network at once, such as a lockdown
+// together with pressure driven flickering.
+type CompositeBehavior struct {
+	mode      CompositeMode
+	behaviors []Behavior
+}
+
+func NewCompositeBehavior(mode CompositeMode, behaviors ...Behavior) *CompositeBehavior {
+	if len(behaviors) == 0 {
+		panic("a composite behavior needs at least one behavior")
+	}
+	return &CompositeBehavior{mode: mode, behaviors: behaviors}
+}
+
+func (b *CompositeBehavior) Name() string {
+	names := make([]string, len(b.behaviors))
+	for i, behavior := range b.behaviors {
+		names[i] = behavior.Name()
+	}
+	// the trailing comma keeps a single behavior a tuple
+	if len(names) == 1 {
+		names[0] += ","
+	}
+	return fmt.Sprintf("Composite(mode=%s, behaviors=(%s))", b.mode, strings.Join(names, ", "))
+}
+
+// Return the behaviors in the order they are applied.
+func (b *CompositeBehavior) Behaviors() []Behavior {
+	behaviors := make([]Behavior, len(b.behaviors))
+	copy(behaviors, b.behaviors)
+	return behaviors
+}
+
+func (b *CompositeBehavior) Reset() {
+	for _, behavior := range b.behaviors {
+		behavior.Reset()
+	}
+}
+
+// Combine the reports of every behavior that makes one. Each statistic is
+// prefixed by the behavior's model and position, such as Lockdown[1].lockdown.
+func (b *CompositeBehavior) Report() map[string][]float64 {
+	report := make(map[string][]float64)
+	for i, behavior := range b.behaviors {
+		reporter, ok := behavior.(ReportingBehavior)
+		if !ok {
+			continue
+		}
+		model := behavior.Name()
+		if open := strings.IndexByte(model, '('); open >= 0 {
+			model = model[:open]
+		}
+		for key, values := range reporter.Report() {
+			report[fmt.Sprintf("%s[%d].%s", model, i, key)] = values
+		}
+	}
+	return report
+}
+
+func (b *CompositeBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	if b.mode == SequenceBehaviors {
+		kept := M
+		for _, behavior := range b.behaviors {
+			kept = behavior.UpdateConnections(D, kept, timeStep, sir)
+		}
+		return kept
+	}
+
+	var kept *mat.Dense
+	for _, behavior := range b.behaviors {
+		next := behavior.UpdateConnections(D, M, timeStep, sir)
+		if kept == nil {
+			// behaviors may return M itself, which must not be modified
+			kept = mat.DenseCopyOf(next)
+		} else {
+			kept.MulElem(kept, next)
+		}
+	}
+	return kept
+}
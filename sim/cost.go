@@ -0,0 +1,87 @@
+package sim
+
+import "gonum.org/v1/gonum/mat"
+
+// What a behavior cost the agents socially during one simulation. An edge of M
+// that is missing from the matrix a behavior returned for a step counts as
+// removed for that step. Edges a behavior adds, like the new ones made by
+// AdaptiveRewiringBehavior, don't make up for removed ones.
+type SocialCost struct {
+	// the number of edges in M
+	NumEdges int
+	// the number of edges removed at each step. Entry i is for time step i+1.
+	EdgesRemoved []int
+	// the number of steps each agent spent without each of its edges added up
+	// over all of its edges
+	EdgeDaysLost []int
+}
+
+func newSocialCost(M *mat.Dense) *SocialCost {
+	N, _ := M.Dims()
+	cost := &SocialCost{EdgesRemoved: make([]int, 0), EdgeDaysLost: make([]int, N)}
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			if M.At(u, v) == 1 {
+				cost.NumEdges++
+			}
+		}
+	}
+	return cost
+}
+
+// Add the edges of M that are missing from D to the cost.
+func (c *SocialCost) record(M, D *mat.Dense) {
+	N, _ := M.Dims()
+	removed := 0
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			if M.At(u, v) == 1 && D.At(u, v) == 0 {
+				removed++
+				c.EdgeDaysLost[u]++
+				c.EdgeDaysLost[v]++
+			}
+		}
+	}
+	c.EdgesRemoved = append(c.EdgesRemoved, removed)
+}
+
+// Return the number of edge-days lost over the whole simulation.
+func (c SocialCost) TotalEdgeDaysLost() int {
+	total := 0
+	for _, removed := range c.EdgesRemoved {
+		total += removed
+	}
+	return total
+}
+
+// Return the fraction of the edge-days the network had that were lost. It is 0
+// for a network without edges or a simulation without steps.
+func (c SocialCost) FractionLost() float64 {
+	possible := c.NumEdges * len(c.EdgesRemoved)
+	if possible == 0 {
+		return 0
+	}
+	return float64(c.TotalEdgeDaysLost()) / float64(possible)
+}
+
+// Return the most edges removed at a single step.
+func (c SocialCost) PeakEdgesRemoved() int {
+	peak := 0
+	for _, removed := range c.EdgesRemoved {
+		if removed > peak {
+			peak = removed
+		}
+	}
+	return peak
+}
+
+// Return the most edge-days lost by a single agent.
+func (c SocialCost) MaxAgentEdgeDaysLost() int {
+	most := 0
+	for _, lost := range c.EdgeDaysLost {
+		if lost > most {
+			most = lost
+		}
+	}
+	return most
+}
@@ -0,0 +1,190 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sets"
+	"gonum.org/v1/gonum/mat"
+)
+
+// The shape of the curve a PressureKernel follows
+type KernelShape int
+
+const (
+	// exp(-Scale*distance)
+	ExponentialKernel KernelShape = iota
+	// (distance+1)^-Scale
+	PowerLawKernel
+	// 1 when distance < Scale and 0 otherwise, which matches NodesWithin
+	StepKernel
+)
+
+func (s KernelShape) String() string {
+	switch s {
+	case ExponentialKernel:
+		return "exponential"
+	case PowerLawKernel:
+		return "power"
+	case StepKernel:
+		return "step"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by KernelShape.String.
+func ParseKernelShape(name string) (KernelShape, error) {
+	for _, shape := range []KernelShape{ExponentialKernel, PowerLawKernel, StepKernel} {
+		if shape.String() == name {
+			return shape, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown kernel %s", name)
+}
+
+// How much pressure an infectious agent puts on an agent that is some number of
+// hops away. An agent at distance 0 always gets a pressure of 1 from itself.
+type PressureKernel struct {
+	Shape KernelShape
+	Scale float64
+}
+
+func (k PressureKernel) Weight(distance float64) float64 {
+	if math.IsInf(distance, 1) {
+		return 0
+	}
+	switch k.Shape {
+	case ExponentialKernel:
+		return math.Exp(-k.Scale * distance)
+	case PowerLawKernel:
+		return math.Pow(distance+1, -k.Scale)
+	case StepKernel:
+		if distance < k.Scale {
+			return 1
+		}
+	}
+	return 0
+}
+
+// The shape of the curve a FlickerResponse follows
+type ResponseShape int
+
+const (
+	// 1 / (1 + exp(-Steepness*(pressure-Midpoint)))
+	LogisticResponse ResponseShape = iota
+	// Steepness*(pressure-Midpoint) kept between 0 and 1
+	LinearResponse
+)
+
+func (s ResponseShape) String() string {
+	switch s {
+	case LogisticResponse:
+		return "logistic"
+	case LinearResponse:
+		return "linear"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by ResponseShape.String.
+func ParseResponseShape(name string) (ResponseShape, error) {
+	for _, shape := range []ResponseShape{LogisticResponse, LinearResponse} {
+		if shape.String() == name {
+			return shape, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown response %s", name)
+}
+
+// Turns an agent's pressure into the chance that it flickers
+type FlickerResponse struct {
+	Shape     ResponseShape
+	Steepness float64
+	Midpoint  float64
+}
+
+func (r FlickerResponse) Probability(pressure float64) float64 {
+	switch r.Shape {
+	case LogisticResponse:
+		return 1 / (1 + math.Exp(-r.Steepness*(pressure-r.Midpoint)))
+	case LinearResponse:
+		return math.Max(0, math.Min(1, r.Steepness*(pressure-r.Midpoint)))
+	}
+	return 0
+}
+
+// Pressure below this is treated as none to ignore rounding left over from
+// adding and removing kernel weights.
+const minPressure = 1e-9
+
+// Like SimplePressureBehavior, but every infectious agent pressures every agent
+// it can reach by an amount that falls off with distance, and pressured agents
+// flicker with a probability that depends on how much pressure they are under.
+type DistancePressureBehavior struct {
+	net         network.Topology
+	kernel      PressureKernel
+	response    FlickerResponse
+	flickerMode FlickerMode
+	pressure    []float64
+	rng         *rand.Rand
+}
+
+func NewDistancePressureBehavior(net network.Topology,
+	rng *rand.Rand,
+	kernel PressureKernel,
+	response FlickerResponse,
+	flickerMode FlickerMode) DistancePressureBehavior {
+
+	return DistancePressureBehavior{
+		net:         net,
+		kernel:      kernel,
+		response:    response,
+		flickerMode: flickerMode,
+		pressure:    make([]float64, net.N()),
+		rng:         rng,
+	}
+}
+
+func (b DistancePressureBehavior) Name() string {
+	return fmt.Sprintf("DistancePressure(kernel=%s, kernel_scale=%f, response=%s, steepness=%f, midpoint=%f, flicker_mode=%s)",
+		b.kernel.Shape, b.kernel.Scale, b.response.Shape, b.response.Steepness, b.response.Midpoint, b.flickerMode)
+}
+
+func (b DistancePressureBehavior) Reset() {
+	for agent := range b.pressure {
+		b.pressure[agent] = 0
+	}
+}
+
+// Return how much pressure each agent is under.
+func (b DistancePressureBehavior) Pressure() []float64 {
+	pressure := make([]float64, len(b.pressure))
+	copy(pressure, b.pressure)
+	return pressure
+}
+
+func (b DistancePressureBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	for agent := range sir.NewlyInfectiousAgents() {
+		b.addPressure(agent, 1)
+	}
+	for agent := range sir.NewlyRemovedAgents() {
+		b.addPressure(agent, -1)
+	}
+
+	flickeringAgents := sets.EmptyIntSet()
+	for agent, pressureValue := range b.pressure {
+		if pressureValue > minPressure && b.rng.Float64() < b.response.Probability(pressureValue) {
+			flickeringAgents.Add(agent)
+		}
+	}
+	return removeFlickeringEdges(M, flickeringAgents, b.flickerMode)
+}
+
+// Add sign times the kernel weight to every agent around source.
+func (b DistancePressureBehavior) addPressure(source int, sign float64) {
+	for agent := range b.pressure {
+		b.pressure[agent] += sign * b.kernel.Weight(b.net.Distance(int64(source), int64(agent)))
+	}
+}
@@ -13,6 +13,35 @@ func Simulate(M *mat.Dense,
 	maxSteps int,
 	rng *rand.Rand) []SIR {
 
+	return simulate(M, sir0, disease, behavior, maxSteps, rng, nil)
+}
+
+// Like Simulate, but also return how many edges of M the behavior removed. The
+// cost has an entry for every step the behavior chose edges for, including the
+// last one, whose SIR isn't returned when the simulation ends early because
+// nothing changed in it.
+func SimulateWithCost(M *mat.Dense,
+	sir0 SIR,
+	disease Disease,
+	behavior Behavior,
+	maxSteps int,
+	rng *rand.Rand) ([]SIR, SocialCost) {
+
+	cost := newSocialCost(M)
+	sirs := simulate(M, sir0, disease, behavior, maxSteps, rng, cost)
+	return sirs, *cost
+}
+
+// Run a simulation and record its social cost if cost isn't nil.
+func simulate(M *mat.Dense,
+	sir0 SIR,
+	disease Disease,
+	behavior Behavior,
+	maxSteps int,
+	rng *rand.Rand,
+	cost *SocialCost) []SIR {
+
+	behavior.Reset()
 	sirs := make([]SIR, maxSteps)
 	sirs[0] = sir0.Copy()
 	D := mat.DenseCopyOf(M)
@@ -26,6 +55,9 @@ func Simulate(M *mat.Dense,
 		// for simulating the disease spread
 		newSir, statesChanged := nextSIR(sirs[step-1], D, disease, rng)
 		sirs[step] = newSir
+		if cost != nil {
+			cost.record(M, D)
+		}
 
 		// find all the agents that are in the removed state. If that number is N,
 		// then the simulation is done.
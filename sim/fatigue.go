@@ -0,0 +1,226 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sets"
+	"gonum.org/v1/gonum/mat"
+)
+
+// The key of the node attribute that AttributeCompliance reads
+const ComplianceKey = "compliance"
+
+// Where the agents' compliance levels come from
+type ComplianceShape int
+
+const (
+	// every agent has a compliance of Mean
+	ConstantCompliance ComplianceShape = iota
+	// uniformly distributed between Mean-Spread and Mean+Spread
+	UniformCompliance
+	// each agent fully complies with probability Mean and otherwise doesn't at all
+	BernoulliCompliance
+	// the compliance attribute of each node
+	AttributeCompliance
+)
+
+func (s ComplianceShape) String() string {
+	switch s {
+	case ConstantCompliance:
+		return "constant"
+	case UniformCompliance:
+		return "uniform"
+	case BernoulliCompliance:
+		return "bernoulli"
+	case AttributeCompliance:
+		return "attribute"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by ComplianceShape.String.
+func ParseComplianceShape(name string) (ComplianceShape, error) {
+	for _, shape := range []ComplianceShape{ConstantCompliance, UniformCompliance, BernoulliCompliance, AttributeCompliance} {
+		if shape.String() == name {
+			return shape, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown compliance %s", name)
+}
+
+// How willing each agent is to distance, from 0 for never to 1 for always
+type ComplianceSource struct {
+	Shape  ComplianceShape
+	Mean   float64
+	Spread float64
+}
+
+// Return the compliance of each agent of net. Every level is kept between 0 and
+// 1. AttributeCompliance panics if a node doesn't have a numeric compliance
+// attribute.
+func (s ComplianceSource) Compliance(net network.Topology, rng *rand.Rand) []float64 {
+	compliance := make([]float64, net.N())
+	for agent := range compliance {
+		switch s.Shape {
+		case ConstantCompliance:
+			compliance[agent] = s.Mean
+		case UniformCompliance:
+			compliance[agent] = s.Mean + s.Spread*(2*rng.Float64()-1)
+		case BernoulliCompliance:
+			if rng.Float64() < s.Mean {
+				compliance[agent] = 1
+			}
+		case AttributeCompliance:
+			compliance[agent] = complianceAttribute(net, agent)
+		}
+		compliance[agent] = math.Max(0, math.Min(1, compliance[agent]))
+	}
+	return compliance
+}
+
+// Write the parameters that the source's shape uses the way FatiguePressure
+// takes them.
+func (s ComplianceSource) params() string {
+	switch s.Shape {
+	case UniformCompliance:
+		return fmt.Sprintf("compliance=%s, compliance_mean=%f, compliance_spread=%f", s.Shape, s.Mean, s.Spread)
+	case AttributeCompliance:
+		return fmt.Sprintf("compliance=%s", s.Shape)
+	}
+	return fmt.Sprintf("compliance=%s, compliance_mean=%f", s.Shape, s.Mean)
+}
+
+func complianceAttribute(net network.Topology, agent int) float64 {
+	attributed, ok := net.(interface {
+		Attribute(id int64, key string) (string, bool)
+	})
+	if !ok {
+		panic("the network doesn't have node attributes")
+	}
+	value, ok := attributed.Attribute(int64(agent), ComplianceKey)
+	if !ok {
+		panic(fmt.Sprintf("agent %d doesn't have a %s attribute", agent, ComplianceKey))
+	}
+	compliance, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("agent %d has a %s of %s, which isn't a number", agent, ComplianceKey, value))
+	}
+	return compliance
+}
+
+// How quickly agents tire of distancing. Each step an agent flickers its fatigue
+// rises by Rate times the distance to 1, and each step it doesn't its fatigue
+// falls by Recovery times its fatigue.
+type Fatigue struct {
+	Rate     float64
+	Recovery float64
+}
+
+// Like SimplePressureBehavior, but pressured agents flicker with probability
+// flickerProbability * compliance * (1 - fatigue), so some agents respond less
+// than others and all of them respond less the longer they have been distancing.
+// The number of flickering agents and the mean fatigue are reported for each
+// step.
+type FatiguePressureBehavior struct {
+	radius             int
+	net                network.Topology
+	flickerProbability float64
+	flickerMode        FlickerMode
+	source             ComplianceSource
+	fatigueDynamics    Fatigue
+	rng                *rand.Rand
+	compliance         []float64
+	pressure           []float64
+	fatigue            []float64
+	numFlickering      []float64
+	meanFatigue        []float64
+}
+
+// Make a FatiguePressureBehavior. The agents' compliance levels are drawn from
+// source once and kept for every simulation.
+func NewFatiguePressureBehavior(net network.Topology,
+	rng *rand.Rand,
+	radius int,
+	flickerProbability float64,
+	flickerMode FlickerMode,
+	source ComplianceSource,
+	fatigue Fatigue) *FatiguePressureBehavior {
+
+	b := &FatiguePressureBehavior{
+		radius:             radius,
+		net:                net,
+		flickerProbability: flickerProbability,
+		flickerMode:        flickerMode,
+		source:             source,
+		fatigueDynamics:    fatigue,
+		rng:                rng,
+		compliance:         source.Compliance(net, rng),
+		pressure:           make([]float64, net.N()),
+		fatigue:            make([]float64, net.N()),
+	}
+	b.Reset()
+	return b
+}
+
+func (b *FatiguePressureBehavior) Name() string {
+	return fmt.Sprintf("FatiguePressure(radius=%d, flicker_probability=%f, flicker_mode=%s, %s, fatigue_rate=%f, recovery_rate=%f)",
+		b.radius, b.flickerProbability, b.flickerMode, b.source.params(),
+		b.fatigueDynamics.Rate, b.fatigueDynamics.Recovery)
+}
+
+// Remove all pressure and fatigue from the agents and clear the report.
+func (b *FatiguePressureBehavior) Reset() {
+	for agent := range b.pressure {
+		b.pressure[agent] = 0
+		b.fatigue[agent] = 0
+	}
+	b.numFlickering = make([]float64, 0)
+	b.meanFatigue = make([]float64, 0)
+}
+
+// Return the compliance level of each agent.
+func (b *FatiguePressureBehavior) Compliance() []float64 {
+	compliance := make([]float64, len(b.compliance))
+	copy(compliance, b.compliance)
+	return compliance
+}
+
+// Return how fatigued each agent is.
+func (b *FatiguePressureBehavior) Fatigue() []float64 {
+	fatigue := make([]float64, len(b.fatigue))
+	copy(fatigue, b.fatigue)
+	return fatigue
+}
+
+func (b *FatiguePressureBehavior) Report() map[string][]float64 {
+	numFlickering := make([]float64, len(b.numFlickering))
+	copy(numFlickering, b.numFlickering)
+	meanFatigue := make([]float64, len(b.meanFatigue))
+	copy(meanFatigue, b.meanFatigue)
+	return map[string][]float64{"flickering": numFlickering, "mean_fatigue": meanFatigue}
+}
+
+func (b *FatiguePressureBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	updateRadiusPressure(b.net, b.radius, b.pressure, sir)
+
+	flickeringAgents := sets.EmptyIntSet()
+	totalFatigue := 0.0
+	for agent, pressureValue := range b.pressure {
+		responsiveness := b.compliance[agent] * (1 - b.fatigue[agent])
+		if pressureValue > 0 && b.rng.Float64() < b.flickerProbability*responsiveness {
+			flickeringAgents.Add(agent)
+			b.fatigue[agent] += b.fatigueDynamics.Rate * (1 - b.fatigue[agent])
+		} else {
+			b.fatigue[agent] -= b.fatigueDynamics.Recovery * b.fatigue[agent]
+		}
+		totalFatigue += b.fatigue[agent]
+	}
+	b.numFlickering = append(b.numFlickering, float64(len(flickeringAgents)))
+	b.meanFatigue = append(b.meanFatigue, totalFatigue/float64(len(b.fatigue)))
+
+	return removeFlickeringEdges(M, flickeringAgents, b.flickerMode)
+}
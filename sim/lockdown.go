@@ -0,0 +1,191 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Which edges a lockdown removes first
+type LockdownEdges int
+
+const (
+	// edges chosen at random
+	LockdownRandomEdges LockdownEdges = iota
+	// the edges whose ends have the fewest neighbors in common, which tend to be
+	// the ones between groups
+	LockdownWeakTies
+)
+
+func (e LockdownEdges) String() string {
+	switch e {
+	case LockdownRandomEdges:
+		return "random"
+	case LockdownWeakTies:
+		return "weak_ties"
+	}
+	return "unknown"
+}
+
+// Parse the names returned by LockdownEdges.String.
+func ParseLockdownEdges(name string) (LockdownEdges, error) {
+	for _, edges := range []LockdownEdges{LockdownRandomEdges, LockdownWeakTies} {
+		if edges.String() == name {
+			return edges, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown lockdown edges %s", name)
+}
+
+// When a lockdown starts and stops
+type LockdownPolicy struct {
+	// a lockdown is ordered once at least this fraction of agents are infectious
+	StartThreshold float64
+	// and can be lifted once the fraction is at or below this, which should be
+	// lower than StartThreshold so that the lockdown doesn't flip on and off
+	StopThreshold float64
+	// the number of steps between ordering a lockdown and it taking effect
+	Delay int
+	// the fewest steps a lockdown lasts
+	MinDuration int
+	// the fraction of edges removed during a lockdown
+	Fraction float64
+	Edges    LockdownEdges
+}
+
+func (p LockdownPolicy) check() error {
+	if p.Fraction < 0 || p.Fraction > 1 {
+		return fmt.Errorf("the fraction of edges to remove (%f) isn't between 0 and 1", p.Fraction)
+	}
+	if p.Delay < 0 || p.MinDuration < 0 {
+		return fmt.Errorf("the delay (%d) and minimum duration (%d) can't be negative", p.Delay, p.MinDuration)
+	}
+	if p.StopThreshold > p.StartThreshold {
+		return fmt.Errorf("the stop threshold (%f) is above the start threshold (%f)",
+			p.StopThreshold, p.StartThreshold)
+	}
+	return nil
+}
+
+// A policy that removes a fraction of all edges while many agents are
+// infectious. The edges are chosen when the lockdown starts and stay removed
+// until it is lifted.
+type LockdownBehavior struct {
+	net    network.Topology
+	policy LockdownPolicy
+	rng    *rand.Rand
+	// the time step a lockdown was ordered to start at, or -1
+	startStep int
+	active    bool
+	// the edges removed by the current lockdown
+	removed [][2]int
+	// 1 for each step the lockdown was in effect and 0 otherwise
+	inEffect []float64
+}
+
+// Make a LockdownBehavior. It panics if the policy's fraction isn't between 0
+// and 1, if its delay or minimum duration is negative, or if its stop threshold
+// is above its start threshold.
+func NewLockdownBehavior(net network.Topology, rng *rand.Rand, policy LockdownPolicy) *LockdownBehavior {
+	if err := policy.check(); err != nil {
+		panic(err)
+	}
+	b := &LockdownBehavior{net: net, policy: policy, rng: rng}
+	b.Reset()
+	return b
+}
+
+func (b *LockdownBehavior) Name() string {
+	p := b.policy
+	return fmt.Sprintf("Lockdown(start_threshold=%f, stop_threshold=%f, delay=%d, min_duration=%d, fraction=%f, edges=%s)",
+		p.StartThreshold, p.StopThreshold, p.Delay, p.MinDuration, p.Fraction, p.Edges)
+}
+
+func (b *LockdownBehavior) Reset() {
+	b.startStep = -1
+	b.active = false
+	b.removed = nil
+	b.inEffect = make([]float64, 0)
+}
+
+// Report whether the lockdown was in effect at each step.
+func (b *LockdownBehavior) Report() map[string][]float64 {
+	inEffect := make([]float64, len(b.inEffect))
+	copy(inEffect, b.inEffect)
+	return map[string][]float64{"lockdown": inEffect}
+}
+
+func (b *LockdownBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir SIR) *mat.Dense {
+	prevalence := float64(len(sir.InfectiousAgents())) / float64(len(sir.I))
+	switch {
+	case b.active:
+		if timeStep-b.startStep >= b.policy.MinDuration && prevalence <= b.policy.StopThreshold {
+			b.active = false
+			b.startStep = -1
+			b.removed = nil
+		}
+	case b.startStep < 0:
+		if prevalence >= b.policy.StartThreshold {
+			b.startStep = timeStep + b.policy.Delay
+		}
+	}
+	if !b.active && b.startStep >= 0 && timeStep >= b.startStep {
+		b.active = true
+		b.removed = b.chooseEdges(b.net.M())
+	}
+
+	if b.active {
+		b.inEffect = append(b.inEffect, 1)
+		R := mat.DenseCopyOf(M)
+		for _, e := range b.removed {
+			R.Set(e[0], e[1], 0)
+			R.Set(e[1], e[0], 0)
+		}
+		return R
+	}
+	b.inEffect = append(b.inEffect, 0)
+	return M
+}
+
+// Return the edges of M that the policy removes. They are chosen from the
+// network rather than the matrix passed to UpdateConnections so that other
+// behaviors removing edges first don't change which ones a lockdown picks.
+func (b *LockdownBehavior) chooseEdges(M *mat.Dense) [][2]int {
+	N, _ := M.Dims()
+	edges := make([][2]int, 0)
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			if M.At(u, v) == 1 {
+				edges = append(edges, [2]int{u, v})
+			}
+		}
+	}
+	b.rng.Shuffle(len(edges), func(i, j int) { edges[i], edges[j] = edges[j], edges[i] })
+	if b.policy.Edges == LockdownWeakTies {
+		// the shuffle breaks ties between edges with the same number of common neighbors
+		common := make([]int, len(edges))
+		for i, e := range edges {
+			for w := 0; w < N; w++ {
+				if M.At(e[0], w) == 1 && M.At(e[1], w) == 1 {
+					common[i]++
+				}
+			}
+		}
+		order := make([]int, len(edges))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool { return common[order[i]] < common[order[j]] })
+		sorted := make([][2]int, len(edges))
+		for i, index := range order {
+			sorted[i] = edges[index]
+		}
+		edges = sorted
+	}
+
+	numToRemove := int(b.policy.Fraction * float64(len(edges)))
+	return edges[:numToRemove]
+}
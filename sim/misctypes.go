@@ -86,6 +86,28 @@ func (sir SIR) InfectiousAgents() sets.IntSet {
 	return theInfectious
 }
 
+// Return the agents that became infectious on the last step.
+func (sir SIR) NewlyInfectiousAgents() sets.IntSet {
+	newlyInfectious := sets.EmptyIntSet()
+	for agent, timeInState := range sir.I {
+		if timeInState == 1 {
+			newlyInfectious.Add(agent)
+		}
+	}
+	return newlyInfectious
+}
+
+// Return the agents that were removed on the last step.
+func (sir SIR) NewlyRemovedAgents() sets.IntSet {
+	newlyRemoved := sets.EmptyIntSet()
+	for agent, timeInState := range sir.R {
+		if timeInState == 1 {
+			newlyRemoved.Add(agent)
+		}
+	}
+	return newlyRemoved
+}
+
 func (sir SIR) SusceptibleAgents() sets.IntSet {
 	theSusceptible := sets.EmptyIntSet()
 	for agent, timeInState := range sir.S {
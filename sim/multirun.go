@@ -2,6 +2,7 @@ package sim
 
 import (
 	"math/rand"
+	"sync"
 
 	"github.com/GaudiestTooth17/irn-sim/network"
 	"gonum.org/v1/gonum/mat"
@@ -40,11 +41,11 @@ func msfsrReturnToChan(M *mat.Dense,
 // with the provided seed each time MultiSimForSurvivalRate is called. The returned slice
 // contains the survival rates in arbitrary order, thus this function should be used
 // for classes of networks where the goal is to generate a distribution of outcomes
-// for all the classes as a whole.
+// for all the classes as a whole. Behaviors are given a View of their network.
 func SimOnManyNetworksForSurvivalRate(nets []*network.AdjacencyList,
 	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
 	disease Disease,
-	makeBehavior func(*network.AdjacencyList, *rand.Rand) Behavior,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
 	maxSteps int,
 	seed int64,
 	numSimsPerNet int) []float64 {
@@ -54,11 +55,11 @@ func SimOnManyNetworksForSurvivalRate(nets []*network.AdjacencyList,
 
 	// send data to channel
 	for _, net := range nets {
-		M := net.M()
+		view := net.View()
 		rng := rand.New(rand.NewSource(seed))
-		sir0 := makeSir0(net.N(), 1, rng)
-		behavior := makeBehavior(net, rng)
-		go msfsrReturnToChan(M, sir0, disease, behavior, maxSteps, rng,
+		sir0 := makeSir0(view.N(), 1, rng)
+		behavior := makeBehavior(view, rng)
+		go msfsrReturnToChan(view.M(), sir0, disease, behavior, maxSteps, rng,
 			numSimsPerNet, survivalRateChan)
 	}
 
@@ -74,3 +75,184 @@ func SimOnManyNetworksForSurvivalRate(nets []*network.AdjacencyList,
 
 	return survivalRates
 }
+
+// Run numSims simulations on a single network with at most numWorkers running at
+// once. Every simulation shares one View of the network, so the adjacency and
+// distance matrices are only computed once. Simulation i uses a *rand.Rand seeded
+// with seed+i, so the returned survival rates are in simulation order and don't
+// depend on how the simulations are scheduled.
+func SimOnNetworkForSurvivalRate(net *network.AdjacencyList,
+	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
+	disease Disease,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
+	maxSteps int,
+	seed int64,
+	numSims int,
+	numWorkers int) []float64 {
+
+	survivalRates, _ := simOnNetwork(net, makeSir0, disease, makeBehavior, maxSteps, seed, numSims, numWorkers, false)
+	return survivalRates
+}
+
+// Like SimOnNetworkForSurvivalRate, but also return the social cost of each
+// simulation so that survival can be weighed against lost connections.
+func SimOnNetworkForSurvivalAndCost(net *network.AdjacencyList,
+	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
+	disease Disease,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
+	maxSteps int,
+	seed int64,
+	numSims int,
+	numWorkers int) ([]float64, []SocialCost) {
+
+	return simOnNetwork(net, makeSir0, disease, makeBehavior, maxSteps, seed, numSims, numWorkers, true)
+}
+
+// Only record the costs if withCost is true, since it takes a pass over the
+// adjacency matrix at every step.
+func simOnNetwork(net *network.AdjacencyList,
+	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
+	disease Disease,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
+	maxSteps int,
+	seed int64,
+	numSims int,
+	numWorkers int,
+	withCost bool) ([]float64, []SocialCost) {
+
+	view := net.View()
+	survivalRates := make([]float64, numSims)
+	var costs []SocialCost
+	if withCost {
+		costs = make([]SocialCost, numSims)
+	}
+	simNums := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range simNums {
+				rng := rand.New(rand.NewSource(seed + int64(i)))
+				sir0 := makeSir0(view.N(), 1, rng)
+				behavior := makeBehavior(view, rng)
+				if withCost {
+					var sirs []SIR
+					sirs, costs[i] = SimulateWithCost(view.M(), sir0, disease, behavior, maxSteps, rng)
+					survivalRates[i] = GetSurvivalPercentage(sirs)
+				} else {
+					survivalRates[i] = SimForSurvivalRate(view.M(), sir0, disease, behavior, maxSteps, rng)
+				}
+			}
+		}()
+	}
+	for i := 0; i < numSims; i++ {
+		simNums <- i
+	}
+	close(simNums)
+	wg.Wait()
+	return survivalRates, costs
+}
+
+// Like SimOnManyNetworksForSurvivalRate, but the networks are received from a
+// channel so that simulations can start before every network has been loaded.
+// At most numWorkers networks are simulated at once, which bounds the memory
+// used by large classes. The function returns once nets is closed and every
+// network received from it has been simulated.
+func SimOnNetworkStreamForSurvivalRate(nets <-chan *network.AdjacencyList,
+	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
+	disease Disease,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
+	maxSteps int,
+	seed int64,
+	numSimsPerNet int,
+	numWorkers int) []float64 {
+
+	survivalRates, _ := simOnNetworkStream(nets, makeSir0, disease, makeBehavior, maxSteps, seed,
+		numSimsPerNet, numWorkers, false)
+	return survivalRates
+}
+
+// Like SimOnNetworkStreamForSurvivalRate, but also return the social cost of
+// each simulation. Entry i of the costs is for the simulation with survival
+// rate i.
+func SimOnNetworkStreamForSurvivalAndCost(nets <-chan *network.AdjacencyList,
+	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
+	disease Disease,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
+	maxSteps int,
+	seed int64,
+	numSimsPerNet int,
+	numWorkers int) ([]float64, []SocialCost) {
+
+	return simOnNetworkStream(nets, makeSir0, disease, makeBehavior, maxSteps, seed,
+		numSimsPerNet, numWorkers, true)
+}
+
+// The results of the simulations on one network
+type networkResults struct {
+	survivalRates []float64
+	costs         []SocialCost
+}
+
+// Only record the costs if withCost is true, since it takes a pass over the
+// adjacency matrix at every step.
+func simOnNetworkStream(nets <-chan *network.AdjacencyList,
+	makeSir0 func(N int, numToInfect int, rng *rand.Rand) SIR,
+	disease Disease,
+	makeBehavior func(network.Topology, *rand.Rand) Behavior,
+	maxSteps int,
+	seed int64,
+	numSimsPerNet int,
+	numWorkers int,
+	withCost bool) ([]float64, []SocialCost) {
+
+	resultChan := make(chan networkResults)
+	doneChan := make(chan struct{})
+
+	for w := 0; w < numWorkers; w++ {
+		go func() {
+			for net := range nets {
+				view := net.View()
+				rng := rand.New(rand.NewSource(seed))
+				sir0 := makeSir0(view.N(), 1, rng)
+				behavior := makeBehavior(view, rng)
+				if !withCost {
+					resultChan <- networkResults{survivalRates: MultiSimForSurvivalRate(view.M(), sir0, disease,
+						behavior, maxSteps, rng, numSimsPerNet)}
+					continue
+				}
+				results := networkResults{
+					survivalRates: make([]float64, numSimsPerNet),
+					costs:         make([]SocialCost, numSimsPerNet),
+				}
+				for i := range results.survivalRates {
+					var sirs []SIR
+					sirs, results.costs[i] = SimulateWithCost(view.M(), sir0, disease, behavior, maxSteps, rng)
+					results.survivalRates[i] = GetSurvivalPercentage(sirs)
+				}
+				resultChan <- results
+			}
+			doneChan <- struct{}{}
+		}()
+	}
+
+	// read data from channel until every worker has finished
+	survivalRates := make([]float64, 0)
+	var costs []SocialCost
+	if withCost {
+		costs = make([]SocialCost, 0)
+	}
+	for workersLeft := numWorkers; workersLeft > 0; {
+		select {
+		case results := <-resultChan:
+			survivalRates = append(survivalRates, results.survivalRates...)
+			if withCost {
+				costs = append(costs, results.costs...)
+			}
+		case <-doneChan:
+			workersLeft--
+		}
+	}
+	return survivalRates, costs
+}
@@ -0,0 +1,453 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/notation"
+)
+
+// The type of a behavior parameter
+type ParamType int
+
+const (
+	IntParam ParamType = iota
+	FloatParam
+	BoolParam
+	StringParam
+	// a tuple of behavior strings such as (Lockdown(...), SimplePressure(...))
+	BehaviorListParam
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case IntParam:
+		return "int"
+	case FloatParam:
+		return "float"
+	case BoolParam:
+		return "bool"
+	case StringParam:
+		return "string"
+	case BehaviorListParam:
+		return "behaviors"
+	}
+	return "unknown"
+}
+
+// A parameter that a registered behavior accepts
+type ParamSpec struct {
+	Name string
+	Type ParamType
+	// the value to use when the parameter isn't given, written the same way it
+	// would be in a behavior string. Parameters without a default are required.
+	Default string
+	// the only values a string parameter may have. Any value is allowed if it's empty.
+	Choices []string
+	// the smallest and largest values a number parameter may have, written like
+	// Default. A bound that is empty isn't checked.
+	Min string
+	Max string
+	Doc string
+}
+
+// Make a behavior for a network from parameters that have already been checked
+// against the behavior's ParamSpecs.
+type BehaviorConstructor func(net network.Topology, rng *rand.Rand, params Params) Behavior
+
+// A behavior that can be made from a string
+type BehaviorSpec struct {
+	Name   string
+	Doc    string
+	Params []ParamSpec
+	// checks that involve more than one parameter, which can be left nil
+	Check func(params Params) error
+	Make  BehaviorConstructor
+}
+
+// Describe how to write the behavior, such as SimplePressure(radius=<int>, flicker_probability=<float>).
+func (spec BehaviorSpec) Usage() string {
+	if len(spec.Params) == 0 {
+		return spec.Name
+	}
+	params := make([]string, len(spec.Params))
+	for i, param := range spec.Params {
+		if len(param.Choices) > 0 {
+			params[i] = fmt.Sprintf("%s=<%s>", param.Name, strings.Join(param.Choices, "|"))
+		} else {
+			params[i] = fmt.Sprintf("%s=<%s>", param.Name, param.Type)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", spec.Name, strings.Join(params, ", "))
+}
+
+// The values of a behavior's parameters. Every parameter in the spec has a value
+// of the type it was declared with.
+type Params struct {
+	values map[string]interface{}
+}
+
+func (p Params) Int(name string) int {
+	return p.values[name].(int)
+}
+
+func (p Params) Float(name string) float64 {
+	return p.values[name].(float64)
+}
+
+func (p Params) Bool(name string) bool {
+	return p.values[name].(bool)
+}
+
+func (p Params) String(name string) string {
+	return p.values[name].(string)
+}
+
+// Return a function for each behavior in a BehaviorListParam that makes it.
+func (p Params) Behaviors(name string) []func(network.Topology, *rand.Rand) Behavior {
+	return p.values[name].([]func(network.Topology, *rand.Rand) Behavior)
+}
+
+var behaviorRegistry = make(map[string]BehaviorSpec)
+
+// Make a behavior available to ParseBehavior. It panics if the name is taken.
+func RegisterBehavior(spec BehaviorSpec) {
+	if _, ok := behaviorRegistry[spec.Name]; ok {
+		panic(fmt.Sprintf("a behavior named %s is already registered", spec.Name))
+	}
+	behaviorRegistry[spec.Name] = spec
+}
+
+// Return every registered behavior sorted by name.
+func Behaviors() []BehaviorSpec {
+	specs := make([]BehaviorSpec, 0, len(behaviorRegistry))
+	for _, spec := range behaviorRegistry {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// Parse a behavior string such as SimplePressure(radius=2, flicker_probability=0.25),
+// which is the same syntax that Behavior.Name uses, and return a function that
+// makes the behavior for a network. Parameters can be given in any order and
+// ones with defaults can be left out.
+func ParseBehavior(description string) (func(network.Topology, *rand.Rand) Behavior, error) {
+	call, err := notation.Parse(description)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := behaviorRegistry[call.Name]
+	if !ok {
+		names := make([]string, 0, len(behaviorRegistry))
+		for _, spec := range Behaviors() {
+			names = append(names, spec.Name)
+		}
+		return nil, fmt.Errorf("unknown behavior %s (available behaviors: %s)",
+			call.Name, strings.Join(names, ", "))
+	}
+
+	given := make(map[string]notation.Value)
+	for _, param := range call.Args {
+		if _, ok := given[param.Name]; ok {
+			return nil, fmt.Errorf("%s: parameter %s is given more than once", spec.Name, param.Name)
+		}
+		given[param.Name] = param
+	}
+	params := Params{values: make(map[string]interface{})}
+	for _, paramSpec := range spec.Params {
+		raw, ok := "", false
+		if param, found := given[paramSpec.Name]; found {
+			raw, ok = param.Raw, true
+			delete(given, paramSpec.Name)
+		} else if paramSpec.Default != "" {
+			raw, ok = paramSpec.Default, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: missing parameter %s (usage: %s)", spec.Name, paramSpec.Name, spec.Usage())
+		}
+		value, err := parseParam(paramSpec.Type, raw)
+		if err != nil && paramSpec.Type == BehaviorListParam {
+			return nil, fmt.Errorf("%s: parameter %s: %v", spec.Name, paramSpec.Name, err)
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: parameter %s must be of type %s, got %s", spec.Name, paramSpec.Name, paramSpec.Type, raw)
+		}
+		if len(paramSpec.Choices) > 0 && !containsString(paramSpec.Choices, raw) {
+			return nil, fmt.Errorf("%s: parameter %s must be one of %s, got %s", spec.Name, paramSpec.Name,
+				strings.Join(paramSpec.Choices, ", "), raw)
+		}
+		if err := checkRange(paramSpec, value); err != nil {
+			return nil, fmt.Errorf("%s: parameter %s %v", spec.Name, paramSpec.Name, err)
+		}
+		params.values[paramSpec.Name] = value
+	}
+	for _, param := range call.Args {
+		if _, unknown := given[param.Name]; unknown {
+			return nil, fmt.Errorf("%s: unknown parameter %s (usage: %s)", spec.Name, param.Name, spec.Usage())
+		}
+	}
+	if spec.Check != nil {
+		if err := spec.Check(params); err != nil {
+			return nil, fmt.Errorf("%s: %v", spec.Name, err)
+		}
+	}
+
+	return func(net network.Topology, rng *rand.Rand) Behavior {
+		return spec.Make(net, rng, params)
+	}, nil
+}
+
+// Return the community labels of a network for behaviors that need them. It
+// panics if the network doesn't have them.
+func communitiesOf(net network.Topology, behaviorName string) []int {
+	labelled, ok := net.(interface{ Communities() ([]int, bool) })
+	if !ok {
+		panic(fmt.Sprintf("%s needs a network with community labels", behaviorName))
+	}
+	communities, ok := labelled.Communities()
+	if !ok {
+		panic(fmt.Sprintf("%s needs a network with community labels", behaviorName))
+	}
+	return communities
+}
+
+func lockdownPolicy(params Params) LockdownPolicy {
+	// the choices have already been checked
+	edges, _ := ParseLockdownEdges(params.String("edges"))
+	return LockdownPolicy{
+		StartThreshold: params.Float("start_threshold"),
+		StopThreshold:  params.Float("stop_threshold"),
+		Delay:          params.Int("delay"),
+		MinDuration:    params.Int("min_duration"),
+		Fraction:       params.Float("fraction"),
+		Edges:          edges,
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Return an error if a number parameter is outside of its bounds.
+func checkRange(spec ParamSpec, value interface{}) error {
+	var x float64
+	switch v := value.(type) {
+	case int:
+		x = float64(v)
+	case float64:
+		x = v
+	default:
+		return nil
+	}
+	if spec.Min != "" {
+		if min, err := strconv.ParseFloat(spec.Min, 64); err == nil && x < min {
+			return fmt.Errorf("must be at least %s, got %v", spec.Min, value)
+		}
+	}
+	if spec.Max != "" {
+		if max, err := strconv.ParseFloat(spec.Max, 64); err == nil && x > max {
+			return fmt.Errorf("must be at most %s, got %v", spec.Max, value)
+		}
+	}
+	return nil
+}
+
+func parseParam(paramType ParamType, raw string) (interface{}, error) {
+	switch paramType {
+	case IntParam:
+		return strconv.Atoi(raw)
+	case FloatParam:
+		return strconv.ParseFloat(raw, 64)
+	case BoolParam:
+		return strconv.ParseBool(raw)
+	case BehaviorListParam:
+		return parseBehaviorList(raw)
+	}
+	return raw, nil
+}
+
+func parseBehaviorList(raw string) ([]func(network.Topology, *rand.Rand) Behavior, error) {
+	list, err := notation.ParseValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	if list.Kind != notation.TupleValue || len(list.Tuple) == 0 {
+		return nil, fmt.Errorf("expected behaviors in parentheses such as (StaticBehavior,), got %s", raw)
+	}
+	makers := make([]func(network.Topology, *rand.Rand) Behavior, len(list.Tuple))
+	for i, element := range list.Tuple {
+		makers[i], err = ParseBehavior(element.Raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return makers, nil
+}
+
+func init() {
+	RegisterBehavior(BehaviorSpec{
+		Name: "StaticBehavior",
+		Doc:  "Agents never change their connections.",
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			return StaticBehavior{}
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "SimplePressure",
+		Doc:  "Agents within radius of an infectious agent are pressured and flicker off their connections.",
+		Params: []ParamSpec{
+			{Name: "radius", Type: IntParam, Min: "0", Doc: "how many hops pressure spreads from an infectious agent"},
+			{Name: "flicker_probability", Type: FloatParam, Min: "0", Max: "1", Doc: "the chance that a pressured agent flickers each step"},
+			{Name: "flicker_mode", Type: StringParam, Default: FlickerAllEdges.String(),
+				Choices: []string{FlickerAllEdges.String(), FlickerBetweenFlickering.String()},
+				Doc:     "all turns off every edge of a flickering agent and pairs only turns off edges between flickering agents"},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			// the choices have already been checked
+			mode, _ := ParseFlickerMode(params.String("flicker_mode"))
+			return NewSimplePressureBehavior(net, rng, params.Int("radius"), params.Float("flicker_probability"), mode)
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "DistancePressure",
+		Doc:  "Infectious agents pressure every agent by an amount that falls off with distance and agents flicker more under more pressure.",
+		Params: []ParamSpec{
+			{Name: "kernel", Type: StringParam, Default: ExponentialKernel.String(),
+				Choices: []string{ExponentialKernel.String(), PowerLawKernel.String(), StepKernel.String()},
+				Doc:     "how pressure falls off with distance"},
+			{Name: "kernel_scale", Type: FloatParam, Default: "1",
+				Doc: "the decay rate, exponent or radius of the kernel"},
+			{Name: "response", Type: StringParam, Default: LogisticResponse.String(),
+				Choices: []string{LogisticResponse.String(), LinearResponse.String()},
+				Doc:     "how pressure is turned into a flicker probability"},
+			{Name: "steepness", Type: FloatParam, Default: "4", Doc: "how quickly the flicker probability rises with pressure"},
+			{Name: "midpoint", Type: FloatParam, Default: "1", Doc: "the pressure where the response is centered"},
+			{Name: "flicker_mode", Type: StringParam, Default: FlickerAllEdges.String(),
+				Choices: []string{FlickerAllEdges.String(), FlickerBetweenFlickering.String()},
+				Doc:     "all turns off every edge of a flickering agent and pairs only turns off edges between flickering agents"},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			// the choices have already been checked
+			shape, _ := ParseKernelShape(params.String("kernel"))
+			responseShape, _ := ParseResponseShape(params.String("response"))
+			mode, _ := ParseFlickerMode(params.String("flicker_mode"))
+			kernel := PressureKernel{Shape: shape, Scale: params.Float("kernel_scale")}
+			response := FlickerResponse{Shape: responseShape, Steepness: params.Float("steepness"), Midpoint: params.Float("midpoint")}
+			return NewDistancePressureBehavior(net, rng, kernel, response, mode)
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "AdaptiveRewiring",
+		Doc:  "Susceptible agents cut their edges to infectious agents and connect to other susceptible agents for the rest of the simulation.",
+		Params: []ParamSpec{
+			{Name: "rate", Type: FloatParam, Min: "0", Max: "1", Doc: "the chance each step that a susceptible agent rewires an edge to an infectious agent"},
+			{Name: "target", Type: StringParam, Default: RewireToRandom.String(),
+				Choices: []string{RewireToRandom.String(), RewireToFriendOfFriend.String()},
+				Doc:     "who the new edge goes to"},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			// the choices have already been checked
+			target, _ := ParseRewireTarget(params.String("target"))
+			return NewAdaptiveRewiringBehavior(net, rng, params.Float("rate"), target)
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "Lockdown",
+		Doc:  "A fraction of all edges are removed while the fraction of infectious agents is high.",
+		Params: []ParamSpec{
+			{Name: "start_threshold", Type: FloatParam, Min: "0", Max: "1", Doc: "the fraction of infectious agents that orders a lockdown"},
+			{Name: "stop_threshold", Type: FloatParam, Min: "0", Max: "1", Doc: "the fraction of infectious agents at or below which a lockdown is lifted"},
+			{Name: "delay", Type: IntParam, Default: "0", Min: "0", Doc: "the number of steps between ordering a lockdown and it taking effect"},
+			{Name: "min_duration", Type: IntParam, Default: "0", Min: "0", Doc: "the fewest steps a lockdown lasts"},
+			{Name: "fraction", Type: FloatParam, Min: "0", Max: "1", Doc: "the fraction of edges removed during a lockdown"},
+			{Name: "edges", Type: StringParam, Default: LockdownRandomEdges.String(),
+				Choices: []string{LockdownRandomEdges.String(), LockdownWeakTies.String()},
+				Doc:     "random removes edges at random and weak_ties removes the edges whose ends share the fewest neighbors first"},
+		},
+		Check: func(params Params) error {
+			return lockdownPolicy(params).check()
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			return NewLockdownBehavior(net, rng, lockdownPolicy(params))
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "CommunityIsolation",
+		Doc:  "Every edge between a community and the rest of the network is cut while more than a fraction of the community is infectious. The network needs community labels.",
+		Params: []ParamSpec{
+			{Name: "prevalence", Type: FloatParam, Default: "0", Min: "0", Max: "1",
+				Doc: "the fraction of a community that has to be infectious for it to be isolated. 0 isolates it at its first infection."},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			return NewCommunityIsolationBehavior(net, communitiesOf(net, "CommunityIsolation"), params.Float("prevalence"))
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "Bubble",
+		Doc:  "Agents only keep the edges inside their community for a period. The network needs community labels.",
+		Params: []ParamSpec{
+			{Name: "start", Type: IntParam, Default: "1", Doc: "the first time step of the bubble"},
+			{Name: "duration", Type: IntParam, Min: "0", Doc: "the number of steps the bubble lasts"},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			return NewBubbleBehavior(net, communitiesOf(net, "Bubble"), params.Int("start"), params.Int("duration"))
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "Composite",
+		Doc:  "Several behaviors act on the network at once.",
+		Params: []ParamSpec{
+			{Name: "behaviors", Type: BehaviorListParam,
+				Doc: "the behaviors to combine, such as (Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=0.5), SimplePressure(radius=2, flicker_probability=0.25))"},
+			{Name: "mode", Type: StringParam, Default: IntersectBehaviors.String(),
+				Choices: []string{IntersectBehaviors.String(), SequenceBehaviors.String()},
+				Doc:     "intersection keeps the edges of the network that every behavior keeps, dropping rewired edges, and sequence gives each behavior the edges kept by the one before it"},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			// the choices have already been checked
+			mode, _ := ParseCompositeMode(params.String("mode"))
+			makers := params.Behaviors("behaviors")
+			behaviors := make([]Behavior, len(makers))
+			for i, makeBehavior := range makers {
+				behaviors[i] = makeBehavior(net, rng)
+			}
+			return NewCompositeBehavior(mode, behaviors...)
+		},
+	})
+	RegisterBehavior(BehaviorSpec{
+		Name: "FatiguePressure",
+		Doc:  "Like SimplePressure, but agents have their own compliance levels and tire of distancing the longer they do it.",
+		Params: []ParamSpec{
+			{Name: "radius", Type: IntParam, Min: "0", Doc: "how many hops pressure spreads from an infectious agent"},
+			{Name: "flicker_probability", Type: FloatParam, Min: "0", Max: "1", Doc: "the chance that a fully compliant, rested, pressured agent flickers each step"},
+			{Name: "flicker_mode", Type: StringParam, Default: FlickerAllEdges.String(),
+				Choices: []string{FlickerAllEdges.String(), FlickerBetweenFlickering.String()},
+				Doc:     "all turns off every edge of a flickering agent and pairs only turns off edges between flickering agents"},
+			{Name: "compliance", Type: StringParam, Default: ConstantCompliance.String(),
+				Choices: []string{ConstantCompliance.String(), UniformCompliance.String(), BernoulliCompliance.String(), AttributeCompliance.String()},
+				Doc:     "where the agents' compliance levels come from. attribute reads the compliance attribute of each node."},
+			{Name: "compliance_mean", Type: FloatParam, Default: "1", Min: "0", Max: "1", Doc: "the mean compliance level"},
+			{Name: "compliance_spread", Type: FloatParam, Default: "0", Min: "0", Doc: "how far uniform compliance levels can be from the mean"},
+			{Name: "fatigue_rate", Type: FloatParam, Default: "0.1", Min: "0", Max: "1", Doc: "how quickly agents tire while distancing"},
+			{Name: "recovery_rate", Type: FloatParam, Default: "0.05", Min: "0", Max: "1", Doc: "how quickly agents recover when they aren't distancing"},
+		},
+		Make: func(net network.Topology, rng *rand.Rand, params Params) Behavior {
+			// the choices have already been checked
+			mode, _ := ParseFlickerMode(params.String("flicker_mode"))
+			shape, _ := ParseComplianceShape(params.String("compliance"))
+			source := ComplianceSource{Shape: shape, Mean: params.Float("compliance_mean"), Spread: params.Float("compliance_spread")}
+			fatigue := Fatigue{Rate: params.Float("fatigue_rate"), Recovery: params.Float("recovery_rate")}
+			return NewFatiguePressureBehavior(net, rng, params.Int("radius"), params.Float("flicker_probability"),
+				mode, source, fatigue)
+		},
+	})
+}
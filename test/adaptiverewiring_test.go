@@ -0,0 +1,78 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func countEdges(D *mat.Dense) int {
+	N, _ := D.Dims()
+	count := 0
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			if D.At(u, v) == 1 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestAdaptiveRewiringCutsInfectiousEdges(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	numEdges := countEdges(M)
+	sir := makeSIR(net.N(), map[int]int{0: 1, 55: 1}, nil)
+
+	for _, target := range []sim.RewireTarget{sim.RewireToRandom, sim.RewireToFriendOfFriend} {
+		behavior := sim.NewAdaptiveRewiringBehavior(net, rand.New(rand.NewSource(1)), 1, target)
+		D := behavior.UpdateConnections(M, M, 1, sir)
+		if countEdges(D) != numEdges {
+			t.Errorf("%s: rewiring changed the number of edges from %d to %d.", target, numEdges, countEdges(D))
+		}
+		for _, infectious := range []int{0, 55} {
+			for agent := 0; agent < net.N(); agent++ {
+				if D.At(infectious, agent) == 1 && sir.S[agent] > 0 {
+					t.Errorf("%s: susceptible agent %d is still connected to infectious agent %d.", target, agent, infectious)
+				}
+			}
+		}
+		expected := float64(net.From(0).Len() + net.From(55).Len())
+		if rewirings := behavior.Report()["rewirings"]; len(rewirings) != 1 || rewirings[0] != expected {
+			t.Errorf("%s: expected a report of [%v] rewirings, got %v.", target, expected, rewirings)
+		}
+
+		// the changes last until the behavior is reset
+		next := behavior.UpdateConnections(D, M, 2, makeSIR(net.N(), nil, map[int]int{0: 1, 55: 1}))
+		if !mat.Equal(next, D) {
+			t.Errorf("%s: the rewired edges didn't last.", target)
+		}
+		behavior.Reset()
+		if reset := behavior.UpdateConnections(M, M, 1, makeSIR(net.N(), nil, nil)); !mat.Equal(reset, M) {
+			t.Errorf("%s: Reset didn't restore the original edges.", target)
+		}
+		if len(behavior.Report()["rewirings"]) != 1 {
+			t.Errorf("%s: Reset didn't clear the report.", target)
+		}
+	}
+	if M.At(0, 1) != 1 || !net.HasEdgeBetween(0, 1) {
+		t.Error("Rewiring changed the network.")
+	}
+}
+
+func TestAdaptiveRewiringInSimulation(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	rng := rand.New(rand.NewSource(1))
+	var behavior sim.ReportingBehavior = sim.NewAdaptiveRewiringBehavior(net, rng, .5, sim.RewireToRandom)
+	sirs := sim.Simulate(net.M(), sim.MakeSir0(net.N(), 1, rng), sim.Disease{DaysInfectious: 4, TransProb: .3},
+		behavior, 100, rng)
+	// Simulate stops one step early when nothing changes, so there may be a
+	// report for the step after the last SIR
+	if rewirings := behavior.Report()["rewirings"]; len(rewirings) < len(sirs)-1 || len(rewirings) > len(sirs) {
+		t.Errorf("Expected a report for each of the %d steps, got %d.", len(sirs)-1, len(rewirings))
+	}
+}
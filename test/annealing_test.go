@@ -0,0 +1,83 @@
+package test
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/GaudiestTooth17/irn-sim/annealing"
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+)
+
+func TestAnnealingShrinksDiameter(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	startDiameter := annealing.Diameter(net)
+	checkpointPath := filepath.Join(t.TempDir(), "best.txt")
+	options := annealing.Options{
+		Steps:           300,
+		Schedule:        annealing.ExponentialSchedule(1, .98),
+		KeepConnected:   true,
+		CheckpointPath:  checkpointPath,
+		CheckpointEvery: 100,
+	}
+	result := annealing.Anneal(net, annealing.Diameter, options, rand.New(rand.NewSource(1)))
+
+	if result.BestValue >= startDiameter {
+		t.Errorf("Expected the diameter to shrink from %f, but the best was %f.", startDiameter, result.BestValue)
+	}
+	if annealing.Diameter(result.Best) != result.BestValue {
+		t.Error("The best network doesn't have the best value.")
+	}
+	if result.Best.N() != net.N() || result.Best.Edges().Len() != net.Edges().Len() {
+		t.Error("Annealing changed the number of nodes or edges.")
+	}
+	if annealing.Diameter(net) != startDiameter {
+		t.Error("Annealing changed the starting network.")
+	}
+	if len(result.Trace) != options.Steps {
+		t.Errorf("Expected %d trace entries, got %d.", options.Steps, len(result.Trace))
+	}
+
+	checkpoint := fio.ReadFile(checkpointPath)
+	if annealing.Diameter(checkpoint) != result.BestValue {
+		t.Error("The checkpoint doesn't hold the best network.")
+	}
+}
+
+func TestAnnealingMaximizesClustering(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	options := annealing.Options{
+		Steps:    200,
+		Schedule: annealing.LinearSchedule(.01, 200),
+		Maximize: true,
+	}
+	result := annealing.Anneal(net, annealing.AverageClustering, options, rand.New(rand.NewSource(1)))
+	if result.BestValue <= annealing.AverageClustering(net) {
+		t.Errorf("Expected clustering to increase from %f, but the best was %f.",
+			annealing.AverageClustering(net), result.BestValue)
+	}
+}
+
+func TestAnnealingObjectives(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	communicability := annealing.MeanCommunicability(net)
+	// adding an edge adds walks, so communicability has to go up
+	net.AddEdge(0, 10)
+	if annealing.MeanCommunicability(net) <= communicability {
+		t.Error("Adding an edge didn't increase the mean communicability.")
+	}
+
+	makeSir0 := func(N int, numToInfect int, rng *rand.Rand) sim.SIR {
+		return sim.MakeSir0(N, numToInfect, rng)
+	}
+	makeBehavior := func(net network.Topology, rng *rand.Rand) sim.Behavior {
+		return sim.StaticBehavior{}
+	}
+	survival := annealing.MeanSurvival(makeSir0, sim.Disease{DaysInfectious: 4, TransProb: .2},
+		makeBehavior, 100, 1, 4, 2)
+	if rate := survival(net); rate < 0 || rate >= 1 {
+		t.Errorf("%f isn't a valid mean survival rate.", rate)
+	}
+}
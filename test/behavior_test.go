@@ -0,0 +1,130 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Remembers the time steps it has seen since it was last reset
+type recordingBehavior struct {
+	steps  *[]int
+	resets *int
+}
+
+func (b recordingBehavior) Name() string {
+	return "Recording"
+}
+
+func (b recordingBehavior) UpdateConnections(D *mat.Dense, M *mat.Dense, timeStep int, sir sim.SIR) *mat.Dense {
+	*b.steps = append(*b.steps, timeStep)
+	return M
+}
+
+func (b recordingBehavior) Reset() {
+	*b.steps = (*b.steps)[:0]
+	*b.resets++
+}
+
+func TestReplicatesStartWithCleanBehavior(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	rng := rand.New(rand.NewSource(1))
+	behavior := recordingBehavior{new([]int), new(int)}
+	sir0 := sim.MakeSir0(net.N(), 1, rng)
+	disease := sim.Disease{DaysInfectious: 4, TransProb: .5}
+	sim.MultiSimForSurvivalRate(net.M(), sir0, disease, behavior, 50, rng, 3)
+	if *behavior.resets != 3 {
+		t.Errorf("Expected the behavior to be reset before each of 3 replicates, but it was reset %d times.",
+			*behavior.resets)
+	}
+	if len(*behavior.steps) == 0 || (*behavior.steps)[0] != 1 {
+		t.Errorf("The last replicate didn't start at step 1: %v", *behavior.steps)
+	}
+}
+
+func TestSimplePressureResetClearsPressure(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	// every pressured agent flickers
+	behavior := sim.NewSimplePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerAllEdges)
+	infected := sim.MakeSir0(net.N(), 1, rand.New(rand.NewSource(1)))
+	healthy := sim.MakeSir0(net.N(), 0, rand.New(rand.NewSource(1)))
+
+	behavior.UpdateConnections(M, M, 1, infected)
+	if mat.Equal(behavior.UpdateConnections(M, M, 2, healthy), M) {
+		t.Fatal("Expected pressure from the first step to make agents flicker.")
+	}
+	behavior.Reset()
+	if !mat.Equal(behavior.UpdateConnections(M, M, 1, healthy), M) {
+		t.Error("Pressure from before the reset made agents flicker.")
+	}
+}
+
+// Make an SIR where the given agents have been infectious or removed for the
+// given number of steps and everyone else is susceptible.
+func makeSIR(N int, infectious map[int]int, removed map[int]int) sim.SIR {
+	sir := sim.SIR{S: make([]int, N), I: make([]int, N), R: make([]int, N)}
+	for agent := 0; agent < N; agent++ {
+		if time, ok := infectious[agent]; ok {
+			sir.I[agent] = time
+		} else if time, ok := removed[agent]; ok {
+			sir.R[agent] = time
+		} else {
+			sir.S[agent] = 1
+		}
+	}
+	return sir
+}
+
+func TestPressureChangesOnTransitions(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	N := net.N()
+	behavior := sim.NewSimplePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerAllEdges)
+
+	steps := []struct {
+		sir       sim.SIR
+		flickers  bool
+		situation string
+	}{
+		{makeSIR(N, map[int]int{0: 1}, nil), true, "agent 0 became infectious"},
+		{makeSIR(N, map[int]int{0: 2}, nil), true, "agent 0 is still infectious"},
+		{makeSIR(N, nil, map[int]int{0: 1}), false, "agent 0 was removed"},
+		{makeSIR(N, nil, map[int]int{0: 2}), false, "agent 0 has been removed for a while"},
+		{makeSIR(N, nil, map[int]int{0: 3}), false, "agent 0 has been removed for a while"},
+		// pressure would be negative here if removed agents kept relieving it
+		{makeSIR(N, map[int]int{1: 1}, map[int]int{0: 4}), true, "agent 1 became infectious"},
+	}
+	for i, step := range steps {
+		flickered := !mat.Equal(behavior.UpdateConnections(M, M, i+1, step.sir), M)
+		if flickered != step.flickers {
+			t.Errorf("Step %d (%s): expected flickering to be %v.", i+1, step.situation, step.flickers)
+		}
+	}
+}
+
+func TestFlickerModes(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	sir := makeSIR(net.N(), map[int]int{0: 1}, nil)
+	flickering := net.NodesWithin(0, 2)
+
+	all := sim.NewSimplePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerAllEdges)
+	pairs := sim.NewSimplePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerBetweenFlickering)
+	allD := all.UpdateConnections(M, M, 1, sir)
+	pairsD := pairs.UpdateConnections(M, M, 1, sir)
+
+	for it := net.Edges(); it.Next(); {
+		u, v := int(it.Edge().From().ID()), int(it.Edge().To().ID())
+		uFlickers, vFlickers := flickering.Contains(u), flickering.Contains(v)
+		if allOff := allD.At(u, v) == 0; allOff != (uFlickers || vFlickers) {
+			t.Errorf("all: expected edge %d-%d to be off only if an end flickers.", u, v)
+		}
+		if pairsOff := pairsD.At(u, v) == 0; pairsOff != (uFlickers && vFlickers) {
+			t.Errorf("pairs: expected edge %d-%d to be off only if both ends flicker.", u, v)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	original := fio.ReadFile("../networks/connected-comm-50-10.txt")
+	original.SetEdgeWeight(0, graph.NodesOf(original.From(0))[0].ID(), 2.5)
+	path := filepath.Join(t.TempDir(), "net.irnb")
+	fio.WriteBinary(path, original)
+	copy := fio.ReadBinary(path)
+	checkSameNetwork(t, "binary", original, copy, true)
+	for _, e := range graph.EdgesOf(original.Edges()) {
+		u, v := e.From().ID(), e.To().ID()
+		if original.EdgeWeight(u, v) != copy.EdgeWeight(u, v) {
+			t.Errorf("Edge %d-%d had weight %f, got %f.",
+				u, v, original.EdgeWeight(u, v), copy.EdgeWeight(u, v))
+		}
+	}
+}
+
+func TestReadFileCached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "net.txt")
+	grid := fio.ReadFile("../networks/grid-10-10.txt")
+	fio.WriteGML(path, grid)
+
+	checkSameNetwork(t, "uncached", grid, fio.ReadFileCached(path), true)
+	if _, err := os.Stat(path + fio.BinaryCacheExtension); err != nil {
+		t.Fatalf("The cache wasn't written: %v", err)
+	}
+	checkSameNetwork(t, "cached", grid, fio.ReadFileCached(path), true)
+
+	// a changed source invalidates the cache
+	caves := fio.ReadFile("../networks/cavemen-10-10.txt")
+	fio.WriteGML(path, caves)
+	checkSameNetwork(t, "changed source", caves, fio.ReadFileCached(path), true)
+
+	// a corrupted cache is ignored
+	contents, _ := ioutil.ReadFile(path + fio.BinaryCacheExtension)
+	contents[len(contents)/2] ^= 0xff
+	ioutil.WriteFile(path+fio.BinaryCacheExtension, contents, 0644)
+	checkSameNetwork(t, "corrupted cache", caves, fio.ReadFileCached(path), true)
+}
+
+func TestIterateClassCached(t *testing.T) {
+	originals := []*network.AdjacencyList{
+		fio.ReadFile("../networks/grid-10-10.txt"),
+		fio.ReadFile("../networks/elitist-100.txt"),
+	}
+	classPath := filepath.Join(t.TempDir(), "Binary(N=100).tar.gz")
+	fio.WriteClass(classPath, originals)
+
+	for pass := 0; pass < 2; pass++ {
+		it := fio.IterateClassCached(classPath)
+		count := 0
+		for it.Next() {
+			instance := it.Instance()
+			checkSameNetwork(t, instance.Name, originals[instance.ID], instance.Net, true)
+			count++
+		}
+		it.Close()
+		if count != len(originals) {
+			t.Errorf("Pass %d: expected %d instances, got %d.", pass, len(originals), count)
+		}
+		if _, err := os.Stat(classPath + fio.BinaryCacheExtension); err != nil {
+			t.Fatalf("Pass %d: the cache wasn't written: %v", pass, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/notation"
+)
+
+func TestParseClassName(t *testing.T) {
+	metadata, err := fio.ParseClassName("networks/ConnComm(N_comm=10,ib=(5, 10),num_comms=50,ob=(3, 6)).tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Model != "ConnComm" {
+		t.Errorf("Expected model ConnComm, got %s.", metadata.Model)
+	}
+	ib, ok := metadata.Param("ib")
+	if !ok || ib.Kind != fio.TupleParam || len(ib.Tuple) != 2 || ib.Tuple[0].Int != 5 || ib.Tuple[1].Int != 10 {
+		t.Errorf("ib was parsed as %+v.", ib)
+	}
+	names, values := metadata.Columns()
+	expectedNames := []string{"N_comm", "ib_0", "ib_1", "num_comms", "ob_0", "ob_1"}
+	expectedValues := []string{"10", "5", "10", "50", "3", "6"}
+	if !reflect.DeepEqual(names, expectedNames) || !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("Got columns %v with values %v.", names, values)
+	}
+
+	metadata, err = fio.ParseClassName("ErdosRenyi(N=500,p=0.01).tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := metadata.Param("p"); p.Kind != fio.FloatParam || p.Float != 0.01 {
+		t.Errorf("p was parsed as %+v.", p)
+	}
+	if n, _ := metadata.Param("N"); n.Kind != fio.IntParam || n.Int != 500 {
+		t.Errorf("N was parsed as %+v.", n)
+	}
+
+	if _, err := fio.ParseClassName("Broken(N=(1,2).tar.gz"); err == nil {
+		t.Error("Expected an error for unbalanced parentheses.")
+	}
+}
+
+func TestParseNotation(t *testing.T) {
+	// unlike class names, paths and extensions are left alone
+	call, err := notation.Parse("Model(path=networks/a.tar.gz, n=(1, 2))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Name != "Model" || len(call.Args) != 2 || call.Args[0].Raw != "networks/a.tar.gz" {
+		t.Errorf("Expected Model with a path, got %v.", call)
+	}
+	if n := call.Args[1]; n.Kind != notation.TupleValue || len(n.Tuple) != 2 || n.Tuple[1].Int != 2 {
+		t.Errorf("Expected the tuple (1, 2), got %v.", n)
+	}
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+func TestLouvainFindsCaves(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	communities := network.Louvain(net, rand.New(rand.NewSource(1)))
+	numCommunities := 0
+	for _, c := range communities {
+		if c+1 > numCommunities {
+			numCommunities = c + 1
+		}
+	}
+	if numCommunities != 10 {
+		t.Errorf("Expected 10 communities, but found %d.", numCommunities)
+	}
+
+	again := network.Louvain(net, rand.New(rand.NewSource(1)))
+	if !reflect.DeepEqual(communities, again) {
+		t.Error("Louvain gave different partitions with the same seed.")
+	}
+
+	labelled, _ := net.Communities()
+	if found, given := network.Modularity(net, communities), network.Modularity(net, labelled); found < given-1e-9 {
+		t.Errorf("Louvain modularity %.4f is lower than the labelled modularity %.4f.", found, given)
+	}
+}
+
+func TestLabelPropagationIsDeterministic(t *testing.T) {
+	net := fio.ReadFile("../networks/connected-comm-50-10.txt")
+	first := network.LabelPropagation(net, rand.New(rand.NewSource(7)))
+	second := network.LabelPropagation(net, rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(first, second) {
+		t.Error("LabelPropagation gave different partitions with the same seed.")
+	}
+
+	net.SetCommunities(first)
+	attached, ok := net.Communities()
+	if !ok || !reflect.DeepEqual(attached, first) {
+		t.Error("The community attribute does not match the detected communities.")
+	}
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Count the edges of D between communities and the ones that touch community.
+func countBetweenEdges(D *mat.Dense, communities []int, community int) (between, touching int) {
+	N, _ := D.Dims()
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			if D.At(u, v) == 0 || communities[u] == communities[v] {
+				continue
+			}
+			between++
+			if communities[u] == community || communities[v] == community {
+				touching++
+			}
+		}
+	}
+	return between, touching
+}
+
+func labelledNetwork(t *testing.T) (*network.AdjacencyList, []int) {
+	net := fio.ReadFile("../networks/connected-comm-10-10.txt")
+	communities, ok := net.Communities()
+	if !ok {
+		t.Fatal("The network doesn't have community labels.")
+	}
+	return net, communities
+}
+
+func TestCommunityIsolation(t *testing.T) {
+	net, communities := labelledNetwork(t)
+	M := net.M()
+	N := net.N()
+	allBetween, _ := countBetweenEdges(M, communities, -1)
+	behavior := sim.NewCommunityIsolationBehavior(net.View(), communities, 0)
+
+	D := behavior.UpdateConnections(M, M, 1, makePrevalenceSIR(N, 0))
+	if !mat.Equal(D, M) {
+		t.Error("Edges were cut before there were any infections.")
+	}
+
+	D = behavior.UpdateConnections(M, M, 2, makePrevalenceSIR(N, 1))
+	between, touching := countBetweenEdges(D, communities, communities[0])
+	_, originallyTouching := countBetweenEdges(M, communities, communities[0])
+	if touching != 0 {
+		t.Errorf("%d edges still connect agent 0's community to the rest of the network.", touching)
+	}
+	if between != allBetween-originallyTouching {
+		t.Errorf("Expected only the %d edges of the infected community to be cut, %d were.",
+			originallyTouching, allBetween-between)
+	}
+	if countEdges(M)-countEdges(D) != originallyTouching {
+		t.Error("Edges inside communities were cut.")
+	}
+
+	report := behavior.Report()
+	key := "community_" + strconv.Itoa(communities[0])
+	if isolated := report[key+"_isolated"]; len(isolated) != 2 || isolated[0] != 0 || isolated[1] != 1 {
+		t.Errorf("Expected %s_isolated to be [0 1], got %v.", key, isolated)
+	}
+	if infectious := report[key+"_infectious"]; len(infectious) != 2 || infectious[1] != 1 {
+		t.Errorf("Expected %s_infectious to end with 1, got %v.", key, infectious)
+	}
+
+	// a higher threshold leaves a community with a single infection alone
+	behavior = sim.NewCommunityIsolationBehavior(net, communities, .5)
+	if D := behavior.UpdateConnections(M, M, 1, makePrevalenceSIR(N, 1)); !mat.Equal(D, M) {
+		t.Error("A community was isolated below its prevalence threshold.")
+	}
+}
+
+func TestBubble(t *testing.T) {
+	net, communities := labelledNetwork(t)
+	M := net.M()
+	N := net.N()
+	behavior := sim.NewBubbleBehavior(net, communities, 2, 2)
+	for step, inBubble := range []bool{false, true, true, false} {
+		D := behavior.UpdateConnections(M, M, step+1, makePrevalenceSIR(N, 1))
+		between, _ := countBetweenEdges(D, communities, -1)
+		if inBubble && between != 0 {
+			t.Errorf("Step %d: %d edges between communities weren't cut.", step+1, between)
+		}
+		if !inBubble && !mat.Equal(D, M) {
+			t.Errorf("Step %d: edges were cut outside of the bubble.", step+1)
+		}
+	}
+	if removed := behavior.Report()["community_"+strconv.Itoa(communities[0])+"_removed"]; len(removed) != 4 {
+		t.Errorf("Expected 4 steps of reports, got %d.", len(removed))
+	}
+	behavior.Reset()
+	if len(behavior.Report()) != 0 {
+		t.Error("Reset didn't clear the report.")
+	}
+}
+
+func TestCommunityBehaviorsNeedLabels(t *testing.T) {
+	makeBehavior, err := sim.ParseBehavior("Bubble(duration=3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	net, _ := labelledNetwork(t)
+	behavior := makeBehavior(net.View(), rand.New(rand.NewSource(1)))
+	if _, err := sim.ParseBehavior(behavior.Name()); err != nil {
+		t.Errorf("%s doesn't parse: %v", behavior.Name(), err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Making a Bubble for a network without community labels didn't panic.")
+		}
+	}()
+	// only the Topology methods, so the labels aren't available
+	unlabelled := struct{ network.Topology }{net}
+	makeBehavior(unlabelled, rand.New(rand.NewSource(1)))
+}
+
+func TestViewFollowsCommunities(t *testing.T) {
+	net, communities := labelledNetwork(t)
+	if viewed, ok := net.View().Communities(); !ok || viewed[0] != communities[0] {
+		t.Fatal("The View doesn't have the network's community labels.")
+	}
+	communities[0]++
+	net.SetCommunities(communities)
+	if viewed, _ := net.View().Communities(); viewed[0] != communities[0] {
+		t.Error("The View wasn't remade after the community labels changed.")
+	}
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestCompositeCombinesEdges(t *testing.T) {
+	net, communities := labelledNetwork(t)
+	M := net.M()
+	N := net.N()
+	policy := sim.LockdownPolicy{Fraction: .5, Edges: sim.LockdownRandomEdges}
+	sir := makePrevalenceSIR(N, 1)
+
+	lockdown := sim.NewLockdownBehavior(net, rand.New(rand.NewSource(1)), policy)
+	bubble := sim.NewBubbleBehavior(net, communities, 1, 5)
+	expected := mat.DenseCopyOf(lockdown.UpdateConnections(M, M, 1, sir))
+	expected.MulElem(expected, bubble.UpdateConnections(M, M, 1, sir))
+
+	for _, mode := range []sim.CompositeMode{sim.IntersectBehaviors, sim.SequenceBehaviors} {
+		composite := sim.NewCompositeBehavior(mode,
+			sim.NewLockdownBehavior(net, rand.New(rand.NewSource(1)), policy),
+			sim.NewBubbleBehavior(net, communities, 1, 5))
+		D := composite.UpdateConnections(M, M, 1, sir)
+		if !mat.Equal(D, expected) {
+			t.Errorf("%s: expected %d edges to be kept, got %d.", mode, countEdges(expected), countEdges(D))
+		}
+
+		report := composite.Report()
+		bubbleKey := "Bubble[1].community_" + strconv.Itoa(communities[0]) + "_infectious"
+		for _, key := range []string{"Lockdown[0].lockdown", bubbleKey} {
+			if len(report[key]) != 1 {
+				t.Errorf("%s: expected one step of %s, got %v.", mode, key, report[key])
+			}
+		}
+		composite.Reset()
+		if values := composite.Report()["Lockdown[0].lockdown"]; len(values) != 0 {
+			t.Errorf("%s: Reset didn't reset the behaviors.", mode)
+		}
+	}
+	if !mat.Equal(M, net.M()) {
+		t.Error("The composite behavior changed M.")
+	}
+}
+
+func TestParseComposite(t *testing.T) {
+	net, _ := labelledNetwork(t)
+	descriptions := []string{
+		"Composite(behaviors=(Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=0.5), SimplePressure(radius=2, flicker_probability=0.25)))",
+		"Composite(mode=sequence, behaviors=(Bubble(duration=3),))",
+	}
+	for _, description := range descriptions {
+		makeBehavior, err := sim.ParseBehavior(description)
+		if err != nil {
+			t.Errorf("%s: %v", description, err)
+			continue
+		}
+		behavior := makeBehavior(net, rand.New(rand.NewSource(1)))
+		if _, err := sim.ParseBehavior(behavior.Name()); err != nil {
+			t.Errorf("%s doesn't parse: %v", behavior.Name(), err)
+		}
+		rng := rand.New(rand.NewSource(1))
+		sim.Simulate(net.M(), sim.MakeSir0(net.N(), 1, rng), sim.Disease{DaysInfectious: 4, TransProb: .3},
+			behavior, 20, rng)
+	}
+
+	_, err := sim.ParseBehavior("Composite(behaviors=(SimplePressure(radius=2),))")
+	if err == nil || !strings.Contains(err.Error(), "flicker_probability") {
+		t.Errorf("Expected an error about the missing parameter of the inner behavior, got %v.", err)
+	}
+	if _, err := sim.ParseBehavior("Composite(behaviors=StaticBehavior)"); err == nil {
+		t.Error("Behaviors that aren't in parentheses were accepted.")
+	}
+}
+
+func TestCompositeIntersectDropsRewiredEdges(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	sir := makeSIR(net.N(), map[int]int{0: 1, 55: 1}, nil)
+
+	rewired := sim.NewAdaptiveRewiringBehavior(net, rand.New(rand.NewSource(1)), 1, sim.RewireToRandom).
+		UpdateConnections(M, M, 1, sir)
+	expected := mat.DenseCopyOf(rewired)
+	expected.MulElem(expected, M)
+	if mat.Equal(expected, rewired) {
+		t.Fatal("AdaptiveRewiring didn't add any edges.")
+	}
+
+	intersection := sim.NewCompositeBehavior(sim.IntersectBehaviors,
+		sim.NewAdaptiveRewiringBehavior(net, rand.New(rand.NewSource(1)), 1, sim.RewireToRandom), sim.StaticBehavior{})
+	if D := intersection.UpdateConnections(M, M, 1, sir); !mat.Equal(D, expected) {
+		t.Errorf("Expected only the %d edges of M that weren't rewired, got %d edges.", countEdges(expected), countEdges(D))
+	}
+	sequence := sim.NewCompositeBehavior(sim.SequenceBehaviors,
+		sim.StaticBehavior{}, sim.NewAdaptiveRewiringBehavior(net, rand.New(rand.NewSource(1)), 1, sim.RewireToRandom))
+	if D := sequence.UpdateConnections(M, M, 1, sir); !mat.Equal(D, rewired) {
+		t.Error("The sequence didn't keep the rewired edges.")
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+)
+
+// A simulation that ends early has a cost for the step that didn't change
+// anything even though its SIR isn't returned.
+func costCoversSteps(cost sim.SocialCost, sirs []sim.SIR, maxSteps int) bool {
+	if len(sirs) == maxSteps {
+		return len(cost.EdgesRemoved) == maxSteps-1
+	}
+	return len(cost.EdgesRemoved) == len(sirs)
+}
+
+func TestSocialCost(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	numEdges := countEdges(M)
+	disease := sim.Disease{DaysInfectious: 4, TransProb: .3}
+
+	rng := rand.New(rand.NewSource(1))
+	sirs, cost := sim.SimulateWithCost(M, sim.MakeSir0(net.N(), 1, rng), disease, sim.StaticBehavior{}, 100, rng)
+	if cost.NumEdges != numEdges {
+		t.Errorf("Expected the cost to count %d edges, got %d.", numEdges, cost.NumEdges)
+	}
+	if !costCoversSteps(cost, sirs, 100) {
+		t.Errorf("Expected a cost for each step of the %d SIRs, got %d.", len(sirs), len(cost.EdgesRemoved))
+	}
+	if cost.TotalEdgeDaysLost() != 0 || cost.MaxAgentEdgeDaysLost() != 0 {
+		t.Error("StaticBehavior had a social cost.")
+	}
+
+	// a lockdown that removes half of the edges from the first step until after
+	// the last. The disease always spreads so that the simulation lasts more than
+	// one step.
+	rng = rand.New(rand.NewSource(1))
+	lockdown := sim.NewLockdownBehavior(net, rng, sim.LockdownPolicy{Fraction: .5, MinDuration: 1000})
+	sirs, cost = sim.SimulateWithCost(M, makeSIR(net.N(), map[int]int{55: 1}, nil),
+		sim.Disease{DaysInfectious: 4, TransProb: 1}, lockdown, 100, rng)
+	if len(cost.EdgesRemoved) == 0 {
+		t.Fatal("The simulation ended before the first step.")
+	}
+	for step, removed := range cost.EdgesRemoved {
+		if removed != numEdges/2 {
+			t.Fatalf("Step %d: expected %d edges to be removed, got %d.", step+1, numEdges/2, removed)
+		}
+	}
+	agentTotal := 0
+	for _, lost := range cost.EdgeDaysLost {
+		agentTotal += lost
+	}
+	if agentTotal != 2*cost.TotalEdgeDaysLost() {
+		t.Errorf("Each lost edge-day should be counted once for both of its agents, got %d for %d edge-days.",
+			agentTotal, cost.TotalEdgeDaysLost())
+	}
+	if cost.FractionLost() != .5 || cost.PeakEdgesRemoved() != numEdges/2 {
+		t.Errorf("Expected half of the edge-days to be lost with a peak of %d, got %f and %d.",
+			numEdges/2, cost.FractionLost(), cost.PeakEdgesRemoved())
+	}
+	if !costCoversSteps(cost, sirs, 100) || cost.MaxAgentEdgeDaysLost() == 0 {
+		t.Error("The cost doesn't match the simulation.")
+	}
+}
+
+func TestSimOnNetworkForSurvivalAndCost(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	makeBehavior := func(net network.Topology, rng *rand.Rand) sim.Behavior {
+		return sim.NewSimplePressureBehavior(net, rng, 2, .5, sim.FlickerAllEdges)
+	}
+	survivalRates, costs := sim.SimOnNetworkForSurvivalAndCost(net, sim.MakeSir0,
+		sim.Disease{DaysInfectious: 4, TransProb: .3}, makeBehavior, 100, 1, 8, 4)
+	if len(survivalRates) != 8 || len(costs) != 8 {
+		t.Fatalf("Expected 8 results, got %d survival rates and %d costs.", len(survivalRates), len(costs))
+	}
+	for i, cost := range costs {
+		if cost.NumEdges != countEdges(net.M()) || cost.FractionLost() < 0 || cost.FractionLost() > 1 {
+			t.Errorf("Simulation %d has an invalid cost: %d edges and %f lost.", i, cost.NumEdges, cost.FractionLost())
+		}
+	}
+}
+
+func TestSimOnNetworkStreamForSurvivalAndCost(t *testing.T) {
+	nets := make(chan *network.AdjacencyList)
+	go func() {
+		defer close(nets)
+		for i := 0; i < 3; i++ {
+			nets <- fio.ReadFile("../networks/grid-10-10.txt")
+		}
+	}()
+	makeBehavior := func(net network.Topology, rng *rand.Rand) sim.Behavior {
+		return sim.NewLockdownBehavior(net, rng, sim.LockdownPolicy{Fraction: .5, MinDuration: 1000})
+	}
+	survivalRates, costs := sim.SimOnNetworkStreamForSurvivalAndCost(nets, sim.MakeSir0,
+		sim.Disease{DaysInfectious: 4, TransProb: .3}, makeBehavior, 100, 1, 2, 2)
+	if len(survivalRates) != 6 || len(costs) != 6 {
+		t.Fatalf("Expected 6 results, got %d survival rates and %d costs.", len(survivalRates), len(costs))
+	}
+	for i, cost := range costs {
+		if cost.FractionLost() != .5 {
+			t.Errorf("Simulation %d: expected half of the edge-days to be lost, got %f.", i, cost.FractionLost())
+		}
+	}
+}
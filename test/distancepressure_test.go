@@ -0,0 +1,83 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPressureKernels(t *testing.T) {
+	kernels := []sim.PressureKernel{
+		{Shape: sim.ExponentialKernel, Scale: 1},
+		{Shape: sim.PowerLawKernel, Scale: 2},
+		{Shape: sim.StepKernel, Scale: 2},
+	}
+	for _, kernel := range kernels {
+		if kernel.Weight(0) != 1 {
+			t.Errorf("%s: an agent should put a pressure of 1 on itself, not %f.", kernel.Shape, kernel.Weight(0))
+		}
+		if kernel.Weight(math.Inf(1)) != 0 {
+			t.Errorf("%s: unreachable agents shouldn't be pressured.", kernel.Shape)
+		}
+		for d := 1.0; d < 5; d++ {
+			if kernel.Weight(d) > kernel.Weight(d-1) {
+				t.Errorf("%s: pressure went up from distance %f to %f.", kernel.Shape, d-1, d)
+			}
+		}
+	}
+	if w := (sim.PressureKernel{Shape: sim.PowerLawKernel, Scale: 2}).Weight(1); w != .25 {
+		t.Errorf("Expected a power law kernel with exponent 2 to give .25 at distance 1, got %f.", w)
+	}
+	step := sim.PressureKernel{Shape: sim.StepKernel, Scale: 2}
+	if step.Weight(1) != 1 || step.Weight(2) != 0 {
+		t.Error("The step kernel should match NodesWithin.")
+	}
+
+	logistic := sim.FlickerResponse{Shape: sim.LogisticResponse, Steepness: 4, Midpoint: 1}
+	if logistic.Probability(1) != .5 || logistic.Probability(3) <= logistic.Probability(2) {
+		t.Error("The logistic response should be .5 at its midpoint and rise with pressure.")
+	}
+	linear := sim.FlickerResponse{Shape: sim.LinearResponse, Steepness: .5, Midpoint: 0}
+	if linear.Probability(1) != .5 || linear.Probability(5) != 1 || linear.Probability(-1) != 0 {
+		t.Error("The linear response should be clamped between 0 and 1.")
+	}
+}
+
+func TestDistancePressureFallsOffWithDistance(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	kernel := sim.PressureKernel{Shape: sim.ExponentialKernel, Scale: 1}
+	response := sim.FlickerResponse{Shape: sim.LogisticResponse, Steepness: 4, Midpoint: 1}
+	behavior := sim.NewDistancePressureBehavior(net, rand.New(rand.NewSource(1)), kernel, response, sim.FlickerAllEdges)
+
+	behavior.UpdateConnections(M, M, 1, makeSIR(net.N(), map[int]int{0: 1}, nil))
+	pressure := behavior.Pressure()
+	for agent, p := range pressure {
+		expected := kernel.Weight(net.Distance(0, int64(agent)))
+		if math.Abs(p-expected) > 1e-12 {
+			t.Errorf("Agent %d should be under %f pressure, not %f.", agent, expected, p)
+		}
+	}
+
+	behavior.UpdateConnections(M, M, 2, makeSIR(net.N(), nil, map[int]int{0: 1}))
+	for agent, p := range behavior.Pressure() {
+		if math.Abs(p) > 1e-12 {
+			t.Errorf("Agent %d is still under %f pressure after the infection was removed.", agent, p)
+		}
+	}
+	if D := behavior.UpdateConnections(M, M, 3, makeSIR(net.N(), nil, map[int]int{0: 2})); !mat.Equal(D, M) {
+		t.Error("Agents flickered without any pressure.")
+	}
+
+	makeBehavior, err := sim.ParseBehavior(behavior.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := makeBehavior(net, rand.New(rand.NewSource(1))).Name(); name != behavior.Name() {
+		t.Errorf("Parsing %s made %s.", behavior.Name(), name)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
 	"github.com/GaudiestTooth17/irn-sim/sim"
 )
 
@@ -21,3 +22,54 @@ func TestSimulation(t *testing.T) {
 		t.Errorf("Expected no agents to survive, but %.3f%% did.", survivalPercentage)
 	}
 }
+
+func TestSimOnNetworkStream(t *testing.T) {
+	nets := make(chan *network.AdjacencyList)
+	go func() {
+		defer close(nets)
+		for i := 0; i < 5; i++ {
+			nets <- fio.ReadFile("../networks/grid-10-10.txt")
+		}
+	}()
+	makeSir0 := func(N int, numToInfect int, rng *rand.Rand) sim.SIR {
+		return sim.MakeSir0(N, numToInfect, rng)
+	}
+	makeBehavior := func(net network.Topology, rng *rand.Rand) sim.Behavior {
+		return sim.StaticBehavior{}
+	}
+	disease := sim.Disease{DaysInfectious: 4, TransProb: .2}
+	survivalRates := sim.SimOnNetworkStreamForSurvivalRate(nets, makeSir0, disease,
+		makeBehavior, 100, 1, 3, 2)
+	if len(survivalRates) != 15 {
+		t.Errorf("Expected 15 survival rates, got %d.", len(survivalRates))
+	}
+}
+
+func TestSimOnNetworkSharesView(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	if net.View() != net.View() {
+		t.Error("Expected the same View until the network changes.")
+	}
+	makeSir0 := func(N int, numToInfect int, rng *rand.Rand) sim.SIR {
+		return sim.MakeSir0(N, numToInfect, rng)
+	}
+	makeBehavior := func(net network.Topology, rng *rand.Rand) sim.Behavior {
+		return sim.NewSimplePressureBehavior(net, rng, 2, .25, sim.FlickerAllEdges)
+	}
+	disease := sim.Disease{DaysInfectious: 4, TransProb: .2}
+	survivalRates := sim.SimOnNetworkForSurvivalRate(net, makeSir0, disease, makeBehavior, 100, 1, 8, 4)
+	if len(survivalRates) != 8 {
+		t.Errorf("Expected 8 survival rates, got %d.", len(survivalRates))
+	}
+	for _, rate := range survivalRates {
+		if rate < 0 || rate >= 1 {
+			t.Errorf("%f isn't a valid survival rate.", rate)
+		}
+	}
+
+	view := net.View()
+	net.AddEdge(0, 99)
+	if net.View() == view || view.HasEdgeBetween(0, 99) {
+		t.Error("Changing the network should make a new View and leave the old one alone.")
+	}
+}
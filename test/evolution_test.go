@@ -0,0 +1,79 @@
+package test
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GaudiestTooth17/irn-sim/annealing"
+	"github.com/GaudiestTooth17/irn-sim/evolution"
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+func TestEvolutionWithElitism(t *testing.T) {
+	seed := fio.ReadFile("../networks/grid-10-10.txt")
+	logDir := t.TempDir()
+	options := evolution.Options{
+		PopulationSize:       12,
+		Generations:          8,
+		NumElites:            2,
+		TournamentSize:       3,
+		CrossoverProbability: .5,
+		Crossover:            evolution.UniformEdgeCrossover,
+		Mutation:             evolution.RewireMutation(3),
+		NumWorkers:           4,
+		LogDir:               logDir,
+	}
+	fitness := evolution.Fitness(annealing.AverageClustering)
+	result := evolution.Evolve([]*network.AdjacencyList{seed}, fitness, options, rand.New(rand.NewSource(1)))
+
+	if len(result.History) != options.Generations {
+		t.Fatalf("Expected %d generations, got %d.", options.Generations, len(result.History))
+	}
+	for g := 1; g < len(result.History); g++ {
+		if result.History[g].BestFitness < result.History[g-1].BestFitness {
+			t.Errorf("The best fitness went down in generation %d even though there are elites.", g)
+		}
+	}
+	if result.BestFitness <= fitness(seed) {
+		t.Errorf("Expected the fitness to improve on %f, but the best was %f.", fitness(seed), result.BestFitness)
+	}
+	if fitness(result.Best) != result.BestFitness {
+		t.Error("The best network doesn't have the best fitness.")
+	}
+	last := filepath.Join(logDir, "generation-7.txt")
+	if fitness(fio.ReadFile(last)) != result.History[7].BestFitness {
+		t.Error("The log of the last generation doesn't hold its best network.")
+	}
+	if _, err := os.Stat(filepath.Join(logDir, "log.csv")); err != nil {
+		t.Error(err)
+	}
+
+	options.NumWorkers = 1
+	options.LogDir = ""
+	serial := evolution.Evolve([]*network.AdjacencyList{seed}, fitness, options, rand.New(rand.NewSource(1)))
+	if !reflect.DeepEqual(serial.History, result.History) {
+		t.Error("The number of workers changed the result.")
+	}
+}
+
+func TestCrossoverKeepsSharedEdges(t *testing.T) {
+	a := fio.ReadFile("../networks/grid-10-10.txt")
+	b := evolution.RewireMutation(20)(a, rand.New(rand.NewSource(1)))
+	rng := rand.New(rand.NewSource(2))
+	for _, crossover := range []evolution.Crossover{evolution.UniformEdgeCrossover, evolution.SinglePointCrossover} {
+		child := crossover(a, b, rng)
+		if child.N() != a.N() {
+			t.Errorf("Expected %d nodes, but the child has %d.", a.N(), child.N())
+		}
+		for it := a.Edges(); it.Next(); {
+			u, v := it.Edge().From().ID(), it.Edge().To().ID()
+			if b.HasEdgeBetween(u, v) && !child.HasEdgeBetween(u, v) {
+				t.Errorf("The child is missing edge %d-%d that both parents have.", u, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestComplianceSources(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	rng := rand.New(rand.NewSource(1))
+
+	for _, c := range (sim.ComplianceSource{Shape: sim.ConstantCompliance, Mean: .5}).Compliance(net, rng) {
+		if c != .5 {
+			t.Fatalf("Expected constant compliance of 0.5, got %f.", c)
+		}
+	}
+	for _, c := range (sim.ComplianceSource{Shape: sim.UniformCompliance, Mean: .5, Spread: .2}).Compliance(net, rng) {
+		if c < .3 || c > .7 {
+			t.Fatalf("Uniform compliance %f is outside of [0.3, 0.7].", c)
+		}
+	}
+	numCompliant := 0
+	for _, c := range (sim.ComplianceSource{Shape: sim.BernoulliCompliance, Mean: .3}).Compliance(net, rng) {
+		if c != 0 && c != 1 {
+			t.Fatalf("Bernoulli compliance should be 0 or 1, got %f.", c)
+		}
+		numCompliant += int(c)
+	}
+	if numCompliant < 15 || numCompliant > 45 {
+		t.Errorf("Expected about 30 of 100 agents to comply, got %d.", numCompliant)
+	}
+
+	for id := int64(0); id < int64(net.N()); id++ {
+		net.SetAttribute(id, network.Attribute{Key: sim.ComplianceKey, Value: "0.25"})
+	}
+	for _, c := range (sim.ComplianceSource{Shape: sim.AttributeCompliance}).Compliance(net.View(), rng) {
+		if c != .25 {
+			t.Fatalf("Expected the compliance attribute of 0.25, got %f.", c)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Reading compliance from a network without the attribute didn't panic.")
+		}
+	}()
+	unlabelled := fio.ReadFile("../networks/grid-10-10.txt")
+	(sim.ComplianceSource{Shape: sim.AttributeCompliance}).Compliance(unlabelled, rng)
+}
+
+func TestFatigue(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	N := net.N()
+	pressured := net.NodesWithin(0, 2)
+	full := sim.ComplianceSource{Shape: sim.ConstantCompliance, Mean: 1}
+
+	// agents tire completely after one step of distancing and never recover
+	behavior := sim.NewFatiguePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerAllEdges,
+		full, sim.Fatigue{Rate: 1, Recovery: 0})
+	if D := behavior.UpdateConnections(M, M, 1, makeSIR(N, map[int]int{0: 1}, nil)); mat.Equal(D, M) {
+		t.Error("Rested agents didn't flicker.")
+	}
+	if D := behavior.UpdateConnections(M, M, 2, makeSIR(N, map[int]int{0: 2}, nil)); !mat.Equal(D, M) {
+		t.Error("Exhausted agents flickered.")
+	}
+	if flickering := behavior.Report()["flickering"]; flickering[0] != float64(len(pressured)) || flickering[1] != 0 {
+		t.Errorf("Expected %d agents to flicker and then none, got %v.", len(pressured), flickering)
+	}
+
+	// agents recover once the pressure is gone
+	behavior = sim.NewFatiguePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerAllEdges,
+		full, sim.Fatigue{Rate: .5, Recovery: .5})
+	behavior.UpdateConnections(M, M, 1, makeSIR(N, map[int]int{0: 1}, nil))
+	for agent, fatigue := range behavior.Fatigue() {
+		expected := 0.0
+		if pressured.Contains(agent) {
+			expected = .5
+		}
+		if fatigue != expected {
+			t.Fatalf("Expected agent %d to have a fatigue of %f, got %f.", agent, expected, fatigue)
+		}
+	}
+	behavior.UpdateConnections(M, M, 2, makeSIR(N, nil, map[int]int{0: 1}))
+	behavior.UpdateConnections(M, M, 3, makeSIR(N, nil, map[int]int{0: 2}))
+	if fatigue := behavior.Fatigue()[0]; fatigue != .125 {
+		t.Errorf("Expected the fatigue to halve each step without pressure, got %f.", fatigue)
+	}
+	if meanFatigue := behavior.Report()["mean_fatigue"]; len(meanFatigue) != 3 || meanFatigue[2] >= meanFatigue[0] {
+		t.Errorf("Expected the mean fatigue to fall, got %v.", meanFatigue)
+	}
+	behavior.Reset()
+	if behavior.Fatigue()[0] != 0 || len(behavior.Report()["flickering"]) != 0 {
+		t.Error("Reset didn't clear the fatigue and the report.")
+	}
+
+	// agents that don't comply never flicker
+	behavior = sim.NewFatiguePressureBehavior(net, rand.New(rand.NewSource(1)), 2, 1, sim.FlickerAllEdges,
+		sim.ComplianceSource{Shape: sim.ConstantCompliance, Mean: 0}, sim.Fatigue{})
+	if D := behavior.UpdateConnections(M, M, 1, makeSIR(N, map[int]int{0: 1}, nil)); !mat.Equal(D, M) {
+		t.Error("Agents without any compliance flickered.")
+	}
+}
+
+func TestParseFatiguePressure(t *testing.T) {
+	makeBehavior, err := sim.ParseBehavior("FatiguePressure(radius=2, flicker_probability=0.5, compliance=uniform, compliance_mean=0.6, compliance_spread=0.3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	behavior := makeBehavior(net.View(), rand.New(rand.NewSource(1)))
+	if _, err := sim.ParseBehavior(behavior.Name()); err != nil {
+		t.Errorf("%s doesn't parse: %v", behavior.Name(), err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	sim.Simulate(net.M(), sim.MakeSir0(net.N(), 1, rng), sim.Disease{DaysInfectious: 4, TransProb: .3},
+		behavior, 50, rng)
+}
+
+func TestFatiguePressureNameRoundTrip(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	for id := int64(0); id < int64(net.N()); id++ {
+		net.SetAttribute(id, network.Attribute{Key: sim.ComplianceKey, Value: fmt.Sprint(float64(id%4) / 4)})
+	}
+	view := net.View()
+	for _, source := range []sim.ComplianceSource{
+		{Shape: sim.AttributeCompliance},
+		{Shape: sim.UniformCompliance, Mean: .6, Spread: .3},
+	} {
+		original := sim.NewFatiguePressureBehavior(view, rand.New(rand.NewSource(1)), 2, .5, sim.FlickerAllEdges,
+			source, sim.Fatigue{Rate: .1, Recovery: .05})
+		if source.Shape == sim.AttributeCompliance && strings.Contains(original.Name(), "compliance_mean") {
+			t.Errorf("%s has parameters attribute compliance doesn't use.", original.Name())
+		}
+		makeBehavior, err := sim.ParseBehavior(original.Name())
+		if err != nil {
+			t.Fatalf("%s doesn't parse: %v", original.Name(), err)
+		}
+		parsed := makeBehavior(view, rand.New(rand.NewSource(1))).(*sim.FatiguePressureBehavior)
+		if parsed.Name() != original.Name() || !reflect.DeepEqual(parsed.Compliance(), original.Compliance()) {
+			t.Errorf("%s: parsing its name made %s with different compliance levels.", original.Name(), parsed.Name())
+		}
+	}
+}
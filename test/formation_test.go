@@ -0,0 +1,109 @@
+package test
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/formation"
+)
+
+var formationConfig = formation.Config{
+	N:              200,
+	Rounds:         30,
+	NumGroups:      4,
+	MinDegree:      3,
+	MaxDegree:      6,
+	Baseline:       .01,
+	Homophily:      1,
+	Proximity:      1,
+	ProximityScale: .1,
+	TriadicClosure: .5,
+	Churn:          .05,
+}
+
+func TestFormationPrefersSimilarAgents(t *testing.T) {
+	net := formation.Generate(formationConfig, rand.New(rand.NewSource(1)))
+	if net.N() != formationConfig.N {
+		t.Fatalf("Expected %d agents, got %d.", formationConfig.N, net.N())
+	}
+	communities, ok := net.Communities()
+	if !ok {
+		t.Fatal("Agents should have their group as their community.")
+	}
+	sameGroup := 0
+	numEdges := net.Edges().Len()
+	for it := net.Edges(); it.Next(); {
+		e := it.Edge()
+		if communities[e.From().ID()] == communities[e.To().ID()] {
+			sameGroup++
+		}
+		if e.From().ID() == e.To().ID() {
+			t.Errorf("Agent %d has a tie to itself.", e.From().ID())
+		}
+	}
+	// with 4 groups only a quarter of random ties would be in the same group
+	if float64(sameGroup) < .5*float64(numEdges) {
+		t.Errorf("Only %d of %d ties are between agents in the same group.", sameGroup, numEdges)
+	}
+	for id := int64(0); id < int64(net.N()); id++ {
+		if degree := net.From(id).Len(); degree > formationConfig.MaxDegree {
+			t.Errorf("Agent %d has %d ties, which is more than any agent wants.", id, degree)
+		}
+	}
+
+	again := formation.Generate(formationConfig, rand.New(rand.NewSource(1)))
+	if !reflect.DeepEqual(edgeIDs(net), edgeIDs(again)) {
+		t.Error("The same seed made different networks.")
+	}
+}
+
+func TestFormationClass(t *testing.T) {
+	nets := formation.GenerateClass(formationConfig, 3, 5)
+	path := filepath.Join(t.TempDir(), formationConfig.ClassName()+".tar.gz")
+	fio.WriteClass(path, nets)
+	for i, net := range fio.ReadClass(path) {
+		checkSameNetwork(t, "formation", nets[i], net, true)
+	}
+	metadata, err := fio.ParseClassName(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if param, ok := metadata.Param("degree"); !ok || len(param.Tuple) != 2 || param.Tuple[1].Int != 6 {
+		t.Errorf("The class name didn't keep the degree range: %+v", metadata)
+	}
+	instance := formation.Generate(formationConfig, rand.New(rand.NewSource(6)))
+	checkSameNetwork(t, "instance 1", instance, nets[1], true)
+}
+
+func TestInvalidFormationConfigs(t *testing.T) {
+	invalid := map[string]func(c *formation.Config){
+		"number of agents": func(c *formation.Config) { c.N = 0 },
+		"number of rounds": func(c *formation.Config) { c.Rounds = -1 },
+		"number of groups": func(c *formation.Config) { c.NumGroups = 0 },
+		"degree range":     func(c *formation.Config) { c.MinDegree, c.MaxDegree = 6, 2 },
+		"proximity scale":  func(c *formation.Config) { c.ProximityScale = 0 },
+		"churn":            func(c *formation.Config) { c.Churn = 1.5 },
+	}
+	for expected, change := range invalid {
+		config := formationConfig
+		change(&config)
+		for name, generate := range map[string]func(){
+			"Generate":      func() { formation.Generate(config, rand.New(rand.NewSource(1))) },
+			"GenerateClass": func() { formation.GenerateClass(config, 2, 1) },
+		} {
+			func() {
+				defer func() {
+					err, ok := recover().(error)
+					if !ok || !strings.Contains(err.Error(), expected) {
+						t.Errorf("%s: expected an error about the %s, got %v.", name, expected, err)
+					}
+				}()
+				generate()
+			}()
+		}
+	}
+}
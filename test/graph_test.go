@@ -0,0 +1,117 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/path"
+	"gonum.org/v1/gonum/graph/testgraph"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// Build an AdjacencyList the same way gonum's tests build their weighted
+// undirected graphs. Self loops are skipped and later edges replace earlier ones.
+func adjacencyListBuilder(nodes []graph.Node, edges []testgraph.WeightedLine, _, _ float64) (g graph.Graph, n []graph.Node, e []testgraph.Edge, s, a float64, ok bool) {
+	byID := make(map[int64]graph.Node)
+	for _, u := range nodes {
+		byID[u.ID()] = u
+	}
+	edgeIndex := make(map[[2]int64]int)
+	kept := make([]network.WeightedLink, 0)
+	for _, edge := range edges {
+		uID, vID := edge.From().ID(), edge.To().ID()
+		if uID == vID {
+			continue
+		}
+		if _, ok := byID[uID]; !ok {
+			byID[uID] = edge.From()
+		}
+		if _, ok := byID[vID]; !ok {
+			byID[vID] = edge.To()
+		}
+		link := network.NewWeightedLink(byID[uID], byID[vID], edge.Weight())
+		key := [2]int64{uID, vID}
+		if uID > vID {
+			key = [2]int64{vID, uID}
+		}
+		if i, ok := edgeIndex[key]; ok {
+			kept[i] = link
+			continue
+		}
+		edgeIndex[key] = len(kept)
+		kept = append(kept, link)
+	}
+	if len(kept) == 0 && len(edges) != 0 {
+		return nil, nil, nil, math.NaN(), math.NaN(), false
+	}
+
+	adjList := make(map[int64][]graph.Node)
+	for id, u := range byID {
+		adjList[id] = make([]graph.Node, 0)
+		n = append(n, u)
+	}
+	for _, link := range kept {
+		u, v := link.From(), link.To()
+		adjList[u.ID()] = append(adjList[u.ID()], v)
+		adjList[v.ID()] = append(adjList[v.ID()], u)
+		e = append(e, link)
+	}
+	net := network.NewAdjacencyList(n, adjList)
+	for _, link := range kept {
+		net.SetEdgeWeight(link.From().ID(), link.To().ID(), link.Weight())
+	}
+	return net, n, e, 0, math.Inf(1), true
+}
+
+func TestAdjacencyListConformance(t *testing.T) {
+	var _ graph.WeightedUndirected = &network.AdjacencyList{}
+
+	t.Run("EdgeExistence", func(t *testing.T) {
+		testgraph.EdgeExistence(t, adjacencyListBuilder, true)
+	})
+	t.Run("NodeExistence", func(t *testing.T) {
+		testgraph.NodeExistence(t, adjacencyListBuilder)
+	})
+	t.Run("ReturnAdjacentNodes", func(t *testing.T) {
+		testgraph.ReturnAdjacentNodes(t, adjacencyListBuilder, true, true)
+	})
+	t.Run("ReturnAllEdges", func(t *testing.T) {
+		testgraph.ReturnAllEdges(t, adjacencyListBuilder, true)
+	})
+	t.Run("ReturnAllNodes", func(t *testing.T) {
+		testgraph.ReturnAllNodes(t, adjacencyListBuilder, true)
+	})
+	t.Run("ReturnAllWeightedEdges", func(t *testing.T) {
+		testgraph.ReturnAllWeightedEdges(t, adjacencyListBuilder, true)
+	})
+	t.Run("ReturnEdgeSlice", func(t *testing.T) {
+		testgraph.ReturnEdgeSlice(t, adjacencyListBuilder, true)
+	})
+	t.Run("ReturnWeightedEdgeSlice", func(t *testing.T) {
+		testgraph.ReturnWeightedEdgeSlice(t, adjacencyListBuilder, true)
+	})
+	t.Run("ReturnNodeSlice", func(t *testing.T) {
+		testgraph.ReturnNodeSlice(t, adjacencyListBuilder, true)
+	})
+	t.Run("Weight", func(t *testing.T) {
+		testgraph.Weight(t, adjacencyListBuilder)
+	})
+}
+
+func TestGonumAlgorithmsOnNetwork(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	components := topo.ConnectedComponents(net)
+	if len(components) != 1 {
+		t.Errorf("Expected 1 connected component, but found %d.", len(components))
+	}
+
+	shortest := path.DijkstraFrom(net.Node(0), net)
+	for id := int64(0); id < int64(net.N()); id++ {
+		if math.IsInf(shortest.WeightTo(id), 1) {
+			t.Errorf("Node %d isn't reachable from node 0.", id)
+		}
+	}
+}
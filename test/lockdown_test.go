@@ -0,0 +1,165 @@
+package test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Make an SIR where the first numInfectious agents are infectious.
+func makePrevalenceSIR(N, numInfectious int) sim.SIR {
+	infectious := make(map[int]int)
+	for agent := 0; agent < numInfectious; agent++ {
+		infectious[agent] = 2
+	}
+	return makeSIR(N, infectious, nil)
+}
+
+func TestLockdownPolicy(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	M := net.M()
+	N := net.N()
+	behavior := sim.NewLockdownBehavior(net, rand.New(rand.NewSource(1)), sim.LockdownPolicy{
+		StartThreshold: .1,
+		StopThreshold:  .05,
+		Delay:          1,
+		MinDuration:    2,
+		Fraction:       .5,
+		Edges:          sim.LockdownRandomEdges,
+	})
+
+	steps := []struct {
+		numInfectious int
+		inEffect      bool
+		situation     string
+	}{
+		{10, false, "the lockdown was ordered but delayed"},
+		{10, true, "the delay ended"},
+		{0, true, "the lockdown hasn't lasted its minimum duration"},
+		{8, true, "prevalence is between the thresholds"},
+		{5, false, "prevalence fell to the stop threshold"},
+		{8, false, "prevalence is between the thresholds"},
+	}
+	var lockedDown *mat.Dense
+	for i, step := range steps {
+		D := behavior.UpdateConnections(M, M, i+1, makePrevalenceSIR(N, step.numInfectious))
+		if !step.inEffect {
+			if !mat.Equal(D, M) {
+				t.Errorf("Step %d: edges were removed when %s.", i+1, step.situation)
+			}
+			continue
+		}
+		if removed := countEdges(M) - countEdges(D); removed != countEdges(M)/2 {
+			t.Errorf("Step %d: expected %d edges to be removed when %s, got %d.", i+1, countEdges(M)/2, step.situation, removed)
+		}
+		if lockedDown != nil && !mat.Equal(D, lockedDown) {
+			t.Errorf("Step %d: the removed edges changed during the lockdown.", i+1)
+		}
+		lockedDown = D
+	}
+
+	expected := []float64{0, 1, 1, 1, 0, 0}
+	report := behavior.Report()["lockdown"]
+	if len(report) != len(expected) {
+		t.Fatalf("Expected a report of %v, got %v.", expected, report)
+	}
+	for i := range expected {
+		if report[i] != expected[i] {
+			t.Fatalf("Expected a report of %v, got %v.", expected, report)
+		}
+	}
+	behavior.Reset()
+	if len(behavior.Report()["lockdown"]) != 0 {
+		t.Error("Reset didn't clear the report.")
+	}
+	if D := behavior.UpdateConnections(M, M, 1, makePrevalenceSIR(N, 0)); !mat.Equal(D, M) {
+		t.Error("Reset didn't lift the lockdown.")
+	}
+}
+
+func TestLockdownRemovesWeakTiesFirst(t *testing.T) {
+	net := fio.ReadFile("../networks/connected-comm-10-10.txt")
+	M := net.M()
+	N := net.N()
+	behavior := sim.NewLockdownBehavior(net, rand.New(rand.NewSource(1)), sim.LockdownPolicy{
+		StartThreshold: .01,
+		Fraction:       .1,
+		Edges:          sim.LockdownWeakTies,
+	})
+	D := behavior.UpdateConnections(M, M, 1, makePrevalenceSIR(N, 1))
+
+	commonNeighbors := func(u, v int) int {
+		count := 0
+		for w := 0; w < N; w++ {
+			if M.At(u, w) == 1 && M.At(v, w) == 1 {
+				count++
+			}
+		}
+		return count
+	}
+	mostRemoved, fewestKept := -1, N
+	for u := 0; u < N; u++ {
+		for v := u + 1; v < N; v++ {
+			if M.At(u, v) == 0 {
+				continue
+			}
+			common := commonNeighbors(u, v)
+			if D.At(u, v) == 0 && common > mostRemoved {
+				mostRemoved = common
+			} else if D.At(u, v) == 1 && common < fewestKept {
+				fewestKept = common
+			}
+		}
+	}
+	if mostRemoved < 0 {
+		t.Fatal("No edges were removed.")
+	}
+	if mostRemoved > fewestKept {
+		t.Errorf("An edge with %d common neighbors was removed while one with %d was kept.", mostRemoved, fewestKept)
+	}
+}
+
+func TestParseLockdown(t *testing.T) {
+	makeBehavior, err := sim.ParseBehavior("Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=0.5, edges=weak_ties)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	behavior := makeBehavior(net, rand.New(rand.NewSource(1)))
+	if _, err := sim.ParseBehavior(behavior.Name()); err != nil {
+		t.Errorf("%s doesn't parse: %v", behavior.Name(), err)
+	}
+	if _, ok := behavior.(sim.ReportingBehavior); !ok {
+		t.Error("Lockdown doesn't report when it was in effect.")
+	}
+}
+
+func TestInvalidLockdown(t *testing.T) {
+	invalid := map[string]string{
+		"Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=1.5)":                  "fraction must be at most 1",
+		"Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=-0.5)":                 "fraction must be at least 0",
+		"Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=0.5, delay=-1)":        "delay must be at least 0",
+		"Lockdown(start_threshold=0.1, stop_threshold=0.05, fraction=0.5, min_duration=-1)": "min_duration must be at least 0",
+		"Lockdown(start_threshold=0.05, stop_threshold=0.1, fraction=0.5)":                  "above the start threshold",
+	}
+	for description, expected := range invalid {
+		_, err := sim.ParseBehavior(description)
+		if err == nil {
+			t.Errorf("Expected an error parsing %s.", description)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error for %s to mention '%s', but got: %v", description, expected, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewLockdownBehavior accepted a fraction above 1.")
+		}
+	}()
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	sim.NewLockdownBehavior(net, rand.New(rand.NewSource(1)), sim.LockdownPolicy{Fraction: 2})
+}
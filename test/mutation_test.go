@@ -0,0 +1,104 @@
+package test
+
+import (
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestEdgeMutationsUpdateMatrices(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	M := net.M()
+	// nodes 0 and 10 are in different caves
+	if net.NodesWithin(0, 2).Contains(10) {
+		t.Fatal("Expected node 10 to be farther than 1 hop from node 0.")
+	}
+
+	if !net.AddEdge(0, 10) {
+		t.Fatal("AddEdge didn't add a new edge.")
+	}
+	if net.AddEdge(10, 0) {
+		t.Error("AddEdge added an edge that already existed.")
+	}
+	if M.At(0, 10) != 0 {
+		t.Error("AddEdge changed a matrix that was already returned.")
+	}
+	if M = net.M(); M.At(0, 10) != 1 || M.At(10, 0) != 1 {
+		t.Error("The adjacency matrix wasn't updated by AddEdge.")
+	}
+	if !net.NodesWithin(0, 2).Contains(10) {
+		t.Error("The distance matrix wasn't updated by AddEdge.")
+	}
+
+	net.SetEdgeWeight(0, 10, 3)
+	if !net.RewireEdge(0, 10, 20) {
+		t.Fatal("RewireEdge didn't move the edge.")
+	}
+	if net.HasEdgeBetween(0, 10) || !net.HasEdgeBetween(20, 0) {
+		t.Error("RewireEdge left the edge in the wrong place.")
+	}
+	if net.EdgeWeight(0, 20) != 3 || net.EdgeWeight(0, 10) != 1 {
+		t.Error("RewireEdge didn't move the weight with the edge.")
+	}
+	if M = net.M(); M.At(0, 10) != 0 || M.At(0, 20) != 1 {
+		t.Error("The adjacency matrix wasn't updated by RewireEdge.")
+	}
+
+	if !net.RemoveEdge(20, 0) || net.RemoveEdge(20, 0) {
+		t.Error("RemoveEdge should only remove an edge that exists.")
+	}
+	if net.HasEdgeBetween(0, 20) || net.M().At(20, 0) != 0 {
+		t.Error("RemoveEdge left the edge behind.")
+	}
+}
+
+func TestMutationsRejectSelfLoops(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	numEdges := net.Edges().Len()
+	neighbor := graph.NodesOf(net.From(0))[0].ID()
+	if net.AddEdge(0, 0) {
+		t.Error("AddEdge added a self loop.")
+	}
+	if net.RewireEdge(0, neighbor, 0) {
+		t.Error("RewireEdge made a self loop.")
+	}
+	if net.Edges().Len() != numEdges || net.HasEdgeBetween(0, 0) || !net.HasEdgeBetween(0, neighbor) {
+		t.Error("Rejecting a self loop changed the network.")
+	}
+	if degree := net.From(0).Len(); degree != 9 {
+		t.Errorf("Expected node 0 to keep its 9 neighbors, got %d.", degree)
+	}
+}
+
+func TestNodeMutations(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	N := net.N()
+	numEdges := net.Edges().Len()
+	degree := net.From(5).Len()
+	net.M()
+
+	net.RemoveNode(5)
+	if net.N() != N-1 || net.Node(5) != nil {
+		t.Fatal("RemoveNode didn't remove the node.")
+	}
+	if net.Edges().Len() != numEdges-degree {
+		t.Errorf("Expected %d edges after removing node 5, but there are %d.", numEdges-degree, net.Edges().Len())
+	}
+	for _, v := range graph.NodesOf(net.Nodes()) {
+		if net.HasEdgeBetween(v.ID(), 5) {
+			t.Errorf("Node %d still has an edge to the removed node.", v.ID())
+		}
+	}
+
+	net.AddNode(network.NewVertex(5))
+	net.AddEdge(5, 6)
+	M := net.M()
+	if rows, _ := M.Dims(); rows != N {
+		t.Errorf("Expected a %dx%d adjacency matrix, but it has %d rows.", N, N, rows)
+	}
+	if M.At(5, 6) != 1 || M.At(5, 4) != 0 {
+		t.Error("The adjacency matrix wasn't rebuilt after the node was added back.")
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+func degrees(net *network.AdjacencyList) []int {
+	degrees := make([]int, net.N())
+	for id := range degrees {
+		degrees[id] = net.From(int64(id)).Len()
+	}
+	return degrees
+}
+
+func TestDegreePreservingRewire(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	numEdges := net.Edges().Len()
+	rewired := network.DegreePreservingRewire(net, 10*numEdges, rand.New(rand.NewSource(1)))
+
+	expected, actual := degrees(net), degrees(rewired)
+	for id := range expected {
+		if expected[id] != actual[id] {
+			t.Errorf("Node %d had degree %d, but has degree %d after rewiring.", id, expected[id], actual[id])
+		}
+	}
+	if rewired.Edges().Len() != numEdges {
+		t.Errorf("Expected %d edges, but there are %d.", numEdges, rewired.Edges().Len())
+	}
+	if network.Modularity(rewired, network.Louvain(rewired, rand.New(rand.NewSource(1)))) >=
+		network.Modularity(net, network.Louvain(net, rand.New(rand.NewSource(1)))) {
+		t.Error("Rewiring the cavemen network should have broken up its caves.")
+	}
+	if net.Edges().Len() != numEdges || !net.HasEdgeBetween(0, 1) {
+		t.Error("Rewiring changed the original network.")
+	}
+}
+
+func TestCommunityPreservingRewire(t *testing.T) {
+	net := fio.ReadFile("../networks/cavemen-10-10.txt")
+	communities := network.Louvain(net, rand.New(rand.NewSource(1)))
+	net.SetCommunities(communities)
+	rewired := network.CommunityPreservingRewire(net, 10*net.Edges().Len(), rand.New(rand.NewSource(1)))
+
+	// every node should have the same number of edges into each community
+	for id := 0; id < net.N(); id++ {
+		expected := make(map[int]int)
+		for it := net.From(int64(id)); it.Next(); {
+			expected[communities[it.Node().ID()]]++
+		}
+		actual := make(map[int]int)
+		for it := rewired.From(int64(id)); it.Next(); {
+			actual[communities[it.Node().ID()]]++
+		}
+		for community, count := range expected {
+			if actual[community] != count {
+				t.Errorf("Node %d had %d edges into community %d, but has %d after rewiring.",
+					id, count, community, actual[community])
+			}
+		}
+	}
+}
+
+func TestConfigurationModel(t *testing.T) {
+	net := fio.ReadFile("../networks/barabasi-albert-500-3.txt")
+	resampled := network.ConfigurationModel(net, rand.New(rand.NewSource(1)))
+	if resampled.N() != net.N() {
+		t.Fatalf("Expected %d nodes, but there are %d.", net.N(), resampled.N())
+	}
+	expected, actual := degrees(net), degrees(resampled)
+	lost, numEdgeEnds := 0, 0
+	for id := range expected {
+		numEdgeEnds += expected[id]
+		if actual[id] > expected[id] {
+			t.Errorf("Node %d gained degree (%d to %d).", id, expected[id], actual[id])
+		}
+		lost += expected[id] - actual[id]
+	}
+	// only a few stubs should be lost to self loops and duplicate edges
+	if lost > numEdgeEnds/20 {
+		t.Errorf("%d edge ends were lost when resampling.", lost)
+	}
+}
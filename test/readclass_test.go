@@ -0,0 +1,118 @@
+package test
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+func TestReadClassFromDirAndZip(t *testing.T) {
+	originals := []*network.AdjacencyList{
+		fio.ReadFile("../networks/grid-10-10.txt"),
+		fio.ReadFile("../networks/cavemen-10-10.txt"),
+	}
+	dir := t.TempDir()
+
+	// the class directory also holds a file that isn't an instance
+	classDir := filepath.Join(dir, "class")
+	if err := os.Mkdir(classDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i, net := range originals {
+		fio.WriteGML(filepath.Join(classDir, fmt.Sprintf("instance-%d.txt", i)), net)
+	}
+	fio.WriteGML(filepath.Join(classDir, "notes.txt"), originals[0])
+
+	zipPath := filepath.Join(dir, "class.zip")
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(zipFile)
+	// store the instances out of order to make sure they are sorted
+	for i := len(originals) - 1; i >= 0; i-- {
+		name := fmt.Sprintf("instance-%d.txt", i)
+		w, err := zipWriter.Create("class/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := os.ReadFile(filepath.Join(classDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(contents)
+	}
+	zipWriter.Close()
+	zipFile.Close()
+
+	for _, path := range []string{classDir, zipPath} {
+		nets := fio.ReadClass(path)
+		if len(nets) != len(originals) {
+			t.Fatalf("%s: expected %d instances, got %d.", path, len(originals), len(nets))
+		}
+		for i := range originals {
+			checkSameNetwork(t, path, originals[i], nets[i], true)
+		}
+	}
+}
+
+func TestReadClassCached(t *testing.T) {
+	originals := []*network.AdjacencyList{
+		fio.ReadFile("../networks/elitist-100.txt"),
+		fio.ReadFile("../networks/connected-comm-10-10.txt"),
+	}
+	dir := t.TempDir()
+	classPath := filepath.Join(dir, "Cached(N=100).tar.gz")
+	cacheDir := filepath.Join(dir, "cache")
+	fio.WriteClass(classPath, originals)
+
+	first := fio.ReadClassCached(classPath, cacheDir)
+	cacheFiles, _ := filepath.Glob(filepath.Join(cacheDir, "*"))
+	if len(cacheFiles) != 1 {
+		t.Fatalf("Expected 1 cache file, found %d.", len(cacheFiles))
+	}
+	second := fio.ReadClassCached(classPath, cacheDir)
+	for i := range originals {
+		checkSameNetwork(t, "first read", originals[i], first[i], true)
+		checkSameNetwork(t, "cached read", originals[i], second[i], true)
+	}
+
+	// changing the class must not reuse the old cache
+	fio.WriteClass(classPath, originals[:1])
+	if nets := fio.ReadClassCached(classPath, cacheDir); len(nets) != 1 {
+		t.Errorf("Expected 1 instance after rewriting the class, got %d.", len(nets))
+	}
+}
+
+func TestIterateClass(t *testing.T) {
+	originals := []*network.AdjacencyList{
+		fio.ReadFile("../networks/grid-10-10.txt"),
+		fio.ReadFile("../networks/cavemen-10-10.txt"),
+		fio.ReadFile("../networks/elitist-100.txt"),
+	}
+	classPath := filepath.Join(t.TempDir(), "Iterated(N=100).tar.gz")
+	fio.WriteClass(classPath, originals)
+
+	it := fio.IterateClass(classPath)
+	defer it.Close()
+	count := 0
+	for it.Next() {
+		instance := it.Instance()
+		if instance.Index != count || instance.ID != count {
+			t.Errorf("Expected index and ID %d, got %d and %d.", count, instance.Index, instance.ID)
+		}
+		if expected := fmt.Sprintf("instance-%d.txt", count); instance.Name != expected {
+			t.Errorf("Expected name %s, got %s.", expected, instance.Name)
+		}
+		checkSameNetwork(t, instance.Name, originals[count], instance.Net, true)
+		count++
+	}
+	if count != len(originals) {
+		t.Errorf("Expected %d instances, got %d.", len(originals), count)
+	}
+}
@@ -0,0 +1,150 @@
+package test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+)
+
+// Each file describes a triangle on nodes 0, 1 and 2 with node 3 hanging off of node 2.
+var triangleWithTail = map[string]string{
+	"triangle.txt": "graph [\n node [ id 0 ]\n node [ id 1 ]\n node [ id 2 ]\n node [ id 3 ]\n" +
+		" edge [ source 0 target 1 ]\n edge [ source 1 target 2 ]\n" +
+		" edge [ source 0 target 2 ]\n edge [ source 2 target 3 ]\n]\n",
+	"triangle.edges": "# comment\n0 1\n1 2\n0 2 1.0\n2 3\n",
+	"triangle.csv":   "0,1,1,0\n1,0,1,0\n1,1,0,1\n0,0,1,0\n",
+	"triangle.mtx": "%%MatrixMarket matrix coordinate pattern symmetric\n% comment\n4 4 4\n" +
+		"2 1\n3 2\n3 1\n4 3\n",
+	"triangle.net": "*Vertices 4\n1 \"a\"\n2 \"b\"\n3 \"c\"\n4 \"d\"\n*Edges\n1 2\n2 3\n*Edgeslist\n3 1 4\n",
+	"triangle.graphml": `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="community" attr.type="int"/>
+  <graph edgedefault="undirected">
+    <node id="0"><data key="d0">1</data></node>
+    <node id="1"><data key="d0">1</data></node>
+    <node id="2"><data key="d0">1</data></node>
+    <node id="3"><data key="d0">2</data></node>
+    <edge source="0" target="1"/>
+    <edge source="1" target="2"/>
+    <edge source="0" target="2"/>
+    <edge source="2" target="3"/>
+  </graph>
+</graphml>
+`,
+	"triangle.npy": npyFile([]byte{0, 1, 1, 0, 1, 0, 1, 0, 1, 1, 0, 1, 0, 0, 1, 0}),
+}
+
+var triangleFormats = map[string]fio.Format{
+	"triangle.txt":     fio.GML,
+	"triangle.edges":   fio.EdgeList,
+	"triangle.csv":     fio.AdjacencyMatrix,
+	"triangle.mtx":     fio.MatrixMarket,
+	"triangle.net":     fio.Pajek,
+	"triangle.graphml": fio.GraphML,
+	"triangle.npy":     fio.NPY,
+}
+
+// Build a version 1.0 .npy file holding a 4x4 matrix of unsigned bytes.
+func npyFile(data []byte) string {
+	header := "{'descr': '|u1', 'fortran_order': False, 'shape': (4, 4), }"
+	for (10+len(header)+1)%64 != 0 {
+		header += " "
+	}
+	header += "\n"
+	prefix := []byte("\x93NUMPY\x01\x00")
+	prefix = append(prefix, byte(len(header)), byte(len(header)>>8))
+	return string(prefix) + header + string(data)
+}
+
+func TestReadNetworkDetectsFormats(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range triangleWithTail {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if format := fio.DetectFormat(path); format != triangleFormats[name] {
+			t.Errorf("%s: detected %v, expected %v.", name, format, triangleFormats[name])
+		}
+		checkTriangleWithTail(t, name, fio.ReadNetwork(path))
+	}
+}
+
+func checkTriangleWithTail(t *testing.T, name string, net *network.AdjacencyList) {
+	if net.N() != 4 {
+		t.Errorf("%s: expected 4 nodes, got %d.", name, net.N())
+		return
+	}
+	edges := [][2]int64{{0, 1}, {1, 2}, {0, 2}, {2, 3}}
+	for _, e := range edges {
+		if !net.HasEdgeBetween(e[0], e[1]) || !net.HasEdgeBetween(e[1], e[0]) {
+			t.Errorf("%s: missing edge %d-%d.", name, e[0], e[1])
+		}
+	}
+	if net.HasEdgeBetween(0, 3) || net.HasEdgeBetween(1, 3) {
+		t.Errorf("%s: has an extra edge.", name)
+	}
+}
+
+func TestReadOneBasedEdgeList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cycle.edges")
+	if err := ioutil.WriteFile(path, []byte("1 2\n2 3\n3 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	net := fio.ReadNetwork(path)
+	M := net.M()
+	if N, _ := M.Dims(); N != 3 || countEdges(M) != 3 {
+		t.Fatalf("Expected a 3 node cycle, got %d nodes and %d edges.", N, countEdges(M))
+	}
+	for id := int64(0); id < 3; id++ {
+		if label, _ := net.Attribute(id, "label"); label != strconv.Itoa(int(id)+1) {
+			t.Errorf("Expected node %d to keep its name %d as its label, got %s.", id, id+1, label)
+		}
+	}
+}
+
+func TestReadNPYErrorsAndSignedValues(t *testing.T) {
+	dir := t.TempDir()
+	truncated := filepath.Join(dir, "truncated.npy")
+	if err := ioutil.WriteFile(truncated, []byte("\x93NUMPY\x02\x00\xff\xff\x00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			err, ok := recover().(error)
+			if !ok || !strings.Contains(err.Error(), "parsing error") {
+				t.Errorf("Expected a parsing error for a truncated file, got %v.", err)
+			}
+		}()
+		fio.ReadNPY(truncated)
+	}()
+
+	// signed matrices where -1 and -2 mark the edge 0-1. Read as unsigned, they
+	// would be 255 and 65534.
+	for dtype, entry := range map[string][]byte{"|i1": {0xff}, "<i2": {0xfe, 0xff}} {
+		data := make([]byte, 0)
+		for i := 0; i < 16; i++ {
+			if i == 1 || i == 4 {
+				data = append(data, entry...)
+			} else {
+				data = append(data, make([]byte, len(entry))...)
+			}
+		}
+		signed := filepath.Join(dir, "signed.npy")
+		if err := ioutil.WriteFile(signed, []byte(strings.Replace(npyFile(data), "|u1", dtype, 1)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		net := fio.ReadNPY(signed)
+		if net.N() != 4 || !net.HasEdgeBetween(0, 1) || net.HasEdgeBetween(0, 2) {
+			t.Errorf("%s: expected only the edge 0-1, got %v.", dtype, edgeIDs(net))
+		}
+		if weight, expected := net.EdgeWeight(0, 1), -float64(len(entry)); weight != expected {
+			t.Errorf("%s: expected the edge to have a weight of %v, got %v.", dtype, expected, weight)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/sim"
+)
+
+func TestParseBehaviorFromName(t *testing.T) {
+	net := fio.ReadFile("../networks/grid-10-10.txt")
+	rng := rand.New(rand.NewSource(1))
+	original := sim.NewSimplePressureBehavior(net, rng, 2, .25, sim.FlickerAllEdges)
+	makeBehavior, err := sim.ParseBehavior(original.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed := makeBehavior(net, rng); parsed.Name() != original.Name() {
+		t.Errorf("Expected %s, but parsing its name made %s.", original.Name(), parsed.Name())
+	}
+
+	makeBehavior, err = sim.ParseBehavior("StaticBehavior")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := makeBehavior(net, rng).(sim.StaticBehavior); !ok {
+		t.Error("StaticBehavior didn't make a StaticBehavior.")
+	}
+}
+
+func TestParseBehaviorErrors(t *testing.T) {
+	invalid := map[string]string{
+		"NoSuchBehavior":           "unknown behavior",
+		"SimplePressure(radius=2)": "missing parameter flicker_probability",
+		"SimplePressure(radius=two, flicker_probability=.25)":                  "radius must be of type int",
+		"SimplePressure(radius=2, flicker_probability=.25, x=1)":               "unknown parameter x",
+		"SimplePressure(radius=2, radius=3)":                                   "more than once",
+		"SimplePressure(radius=2, flicker_probability=.25, flicker_mode=some)": "must be one of all, pairs",
+		"SimplePressure(radius=2":                                              "closing parenthesis",
+		"SimplePressure(radius=-1, flicker_probability=.25)":                   "radius must be at least 0",
+		"SimplePressure(radius=2, flicker_probability=1.5)":                    "flicker_probability must be at most 1",
+	}
+	for description, expected := range invalid {
+		_, err := sim.ParseBehavior(description)
+		if err == nil {
+			t.Errorf("Expected an error parsing %s.", description)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error for %s to mention '%s', but got: %v", description, expected, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestGMLRoundTrip(t *testing.T) {
+	original := fio.ReadFile("../networks/connected-comm-10-10.txt")
+	path := filepath.Join(t.TempDir(), "net.txt")
+	fio.WriteGML(path, original)
+	checkSameNetwork(t, "GML", original, fio.ReadFile(path), true)
+}
+
+func TestGMLRoundTripQuotes(t *testing.T) {
+	original := fio.ReadFile("../networks/grid-10-10.txt")
+	label := `say "hi" &quot; bye`
+	original.SetAttribute(0, network.Attribute{Key: "label", Value: label, Quoted: true})
+	path := filepath.Join(t.TempDir(), "net.txt")
+	fio.WriteGML(path, original)
+	read := fio.ReadFile(path)
+	if value, _ := read.Attribute(0, "label"); value != label {
+		t.Errorf("Expected the label %s, got %s.", label, value)
+	}
+	checkSameNetwork(t, "GML with quotes", original, read, true)
+}
+
+func TestEdgeListRoundTrip(t *testing.T) {
+	original := fio.ReadFile("../networks/grid-10-10.txt")
+	path := filepath.Join(t.TempDir(), "net.edges")
+	fio.WriteEdgeList(path, original)
+	checkSameNetwork(t, "edge list", original, fio.ReadEdgeList(path), false)
+}
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	original := fio.ReadFile("../networks/spatial-network.txt")
+	path := filepath.Join(t.TempDir(), "net.graphml")
+	fio.WriteGraphML(path, original)
+	checkSameNetwork(t, "GraphML", original, fio.ReadGraphML(path), true)
+}
+
+func TestWeightedGraphMLRoundTrip(t *testing.T) {
+	original := fio.ReadFile("../networks/spatial-network.txt")
+	for i, e := range edgeIDs(original) {
+		original.SetEdgeWeight(e[0], e[1], float64(i%3)-.5)
+	}
+	path := filepath.Join(t.TempDir(), "net.graphml")
+	fio.WriteGraphML(path, original)
+	read := fio.ReadGraphML(path)
+	checkSameNetwork(t, "weighted GraphML", original, read, true)
+
+	// a second round trip doesn't lose anything either
+	fio.WriteGraphML(path, read)
+	checkSameNetwork(t, "weighted GraphML twice", original, fio.ReadGraphML(path), true)
+}
+
+func TestClassRoundTrip(t *testing.T) {
+	originals := []*network.AdjacencyList{
+		fio.ReadFile("../networks/elitist-100.txt"),
+		fio.ReadFile("../networks/grid-10-10.txt"),
+		fio.ReadFile("../networks/cavemen-10-10.txt"),
+	}
+	path := filepath.Join(t.TempDir(), "RoundTrip(N=100).tar.gz")
+	fio.WriteClass(path, originals)
+	copies := fio.ReadClass(path)
+	if len(copies) != len(originals) {
+		t.Fatalf("Expected %d instances, got %d.", len(originals), len(copies))
+	}
+	for i := range originals {
+		checkSameNetwork(t, "class", originals[i], copies[i], true)
+	}
+}
+
+func checkSameNetwork(t *testing.T, name string, expected, actual *network.AdjacencyList, checkAttributes bool) {
+	t.Helper()
+	if expected.N() != actual.N() {
+		t.Errorf("%s: expected %d nodes, got %d.", name, expected.N(), actual.N())
+		return
+	}
+	if !reflect.DeepEqual(edgeIDs(expected), edgeIDs(actual)) {
+		t.Errorf("%s: the edges changed.", name)
+	}
+	for _, e := range edgeIDs(expected) {
+		if expected.EdgeWeight(e[0], e[1]) != actual.EdgeWeight(e[0], e[1]) {
+			t.Errorf("%s: edge %d-%d had a weight of %f, got %f.", name, e[0], e[1],
+				expected.EdgeWeight(e[0], e[1]), actual.EdgeWeight(e[0], e[1]))
+			return
+		}
+	}
+	if !checkAttributes {
+		return
+	}
+	for id := int64(0); id < int64(expected.N()); id++ {
+		if !reflect.DeepEqual(expected.Attributes(id), actual.Attributes(id)) {
+			t.Errorf("%s: node %d had attributes %v, got %v.",
+				name, id, expected.Attributes(id), actual.Attributes(id))
+			return
+		}
+	}
+}
+
+func edgeIDs(net *network.AdjacencyList) [][2]int64 {
+	ids := make([][2]int64, 0)
+	for _, e := range graph.EdgesOf(net.Edges()) {
+		ids = append(ids, [2]int64{e.From().ID(), e.To().ID()})
+	}
+	return ids
+}
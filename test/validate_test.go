@@ -0,0 +1,183 @@
+package test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	fio "github.com/GaudiestTooth17/irn-sim/fileio"
+	"github.com/GaudiestTooth17/irn-sim/network"
+	"gonum.org/v1/gonum/graph"
+)
+
+// Nodes 1, 2 and 5 (so IDs aren't 0 to N-1), node 2 declared twice, a self loop
+// on 1, a duplicate 1-2 edge, and an edge to the undeclared node 7.
+const brokenGML = `graph [
+  node [
+    id 1
+  ]
+  node [
+    id 2
+    label "two"
+  ]
+
+  node [
+    id 5
+  ]
+  node [
+    id 2
+  ]
+  edge [
+    source 1
+    target 1
+  ]
+  edge [
+    source 1
+    target 2
+  ]
+  edge [
+    source 2
+    target 1
+  ]
+  edge [
+    source 5
+    target 7
+  ]
+]
+`
+
+func TestValidateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.txt")
+	if err := ioutil.WriteFile(path, []byte(brokenGML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[fio.ProblemKind]int)
+	for _, problem := range fio.ValidateFile(path) {
+		counts[problem.Kind]++
+		if problem.Repaired {
+			t.Errorf("%v was marked as repaired.", problem)
+		}
+		if problem.Kind == fio.SelfLoop && (problem.Line != 16 || problem.Column != 3) {
+			t.Errorf("Expected the self loop at line 16 col 3, got %v.", problem)
+		}
+	}
+	expected := map[fio.ProblemKind]int{
+		fio.DuplicateNode:  1,
+		fio.SelfLoop:       1,
+		fio.DuplicateEdge:  1,
+		fio.UndeclaredNode: 1,
+		// N is 3, so 5 and 7 are out of range
+		fio.IDOutOfRange: 2,
+	}
+	for kind, count := range expected {
+		if counts[kind] != count {
+			t.Errorf("Expected %d %v problems, got %d.", count, kind, counts[kind])
+		}
+	}
+
+	net, problems := fio.ReadFileRepaired(path, fio.RepairAll)
+	for _, problem := range problems {
+		if !problem.Repaired {
+			t.Errorf("%v wasn't repaired.", problem)
+		}
+	}
+	// 1, 2, 5 and 7 become 0, 1, 2 and 3
+	if net.N() != 4 {
+		t.Fatalf("Expected 4 nodes, got %d.", net.N())
+	}
+	if net.Edges().Len() != 2 || !net.HasEdgeBetween(0, 1) || !net.HasEdgeBetween(2, 3) {
+		t.Errorf("Expected the edges 0-1 and 2-3, got %v.", edgeIDs(net))
+	}
+	for id, label := range []string{"1", "two", "5", "7"} {
+		if value, _ := net.Attribute(int64(id), "label"); value != label {
+			t.Errorf("Expected node %d to have label %s, got %s.", id, label, value)
+		}
+	}
+	for id := int64(0); id < 4; id++ {
+		if net.Node(id).ID() != id {
+			t.Errorf("Node(%d) returned node %d.", id, net.Node(id).ID())
+		}
+	}
+}
+
+func TestValidateEdgeList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.edges")
+	if err := ioutil.WriteFile(path, []byte("0 0\n0 1\n1 0\n1 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[fio.ProblemKind]int)
+	for _, problem := range fio.ValidateFile(path) {
+		counts[problem.Kind]++
+	}
+	if len(counts) != 2 || counts[fio.SelfLoop] != 1 || counts[fio.DuplicateEdge] != 1 {
+		t.Errorf("Expected a self loop and a duplicate edge, got %v.", counts)
+	}
+
+	net, _ := fio.ReadFileRepaired(path, fio.RepairAll)
+	if net.N() != 3 || net.Edges().Len() != 2 || net.HasEdgeBetween(0, 0) {
+		t.Errorf("Expected the edges 0-1 and 1-2, got %v.", edgeIDs(net))
+	}
+}
+
+func TestValidateNetwork(t *testing.T) {
+	u, v := network.NewVertex(0), network.NewVertex(5)
+	net := network.NewAdjacencyList([]graph.Node{u, v},
+		map[int64][]graph.Node{0: {v}, 5: {u}})
+	problems := fio.ValidateNetwork(net)
+	if len(problems) != 1 || problems[0].Kind != fio.IDOutOfRange || problems[0].Line != 0 {
+		t.Fatalf("Expected node 5 to be out of range, got %v.", problems)
+	}
+
+	repaired, problems := fio.RepairNetwork(net, fio.RepairAll)
+	if len(problems) != 1 || !problems[0].Repaired {
+		t.Errorf("Expected the ID to be repaired, got %v.", problems)
+	}
+	if repaired.N() != 2 || !repaired.HasEdgeBetween(0, 1) {
+		t.Errorf("Expected the edge 0-1, got %v.", edgeIDs(repaired))
+	}
+	if net.Node(5) == nil {
+		t.Error("RepairNetwork changed the original network.")
+	}
+}
+
+func TestValidateGraphMLUndeclaredNode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.graphml")
+	contents := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <graph edgedefault="undirected">
+    <node id="a"/>
+    <node id="b"/>
+    <edge source="a" target="b"/>
+    <edge source="b" target="c"/>
+  </graph>
+</graphml>
+`
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[fio.ProblemKind]int)
+	for _, problem := range fio.ValidateFile(path) {
+		counts[problem.Kind]++
+		if problem.Kind == fio.UndeclaredNode && problem.Node != 2 {
+			t.Errorf("Expected c (node 2) to be undeclared, got %v.", problem)
+		}
+	}
+	if len(counts) != 2 || counts[fio.UndeclaredNode] != 1 || counts[fio.IDOutOfRange] != 1 {
+		t.Errorf("Expected c to be undeclared and out of range, got %v.", counts)
+	}
+
+	// ReadGraphML accepts the file by adding the node
+	net := fio.ReadGraphML(path)
+	if value, _ := net.Attribute(2, "label"); net.N() != 3 || value != "c" {
+		t.Errorf("Expected c to be added as node 2, got %d nodes and the label %s.", net.N(), value)
+	}
+	net, _ = fio.ReadFileRepaired(path, fio.RepairOptions{})
+	if net.Node(2) != nil {
+		t.Error("The undeclared node was added without AddUndeclaredNodes.")
+	}
+	net, _ = fio.ReadFileRepaired(path, fio.RepairAll)
+	if value, _ := net.Attribute(2, "label"); net.Node(2) == nil || value != "c" {
+		t.Errorf("Expected the repair to add c as node 2, got the label %s.", value)
+	}
+}